@@ -16,23 +16,71 @@ const (
 	RETRY_ANALYZER_JOB_URL  = SPECIFIC_JOB_URL + "/analyzer/%s/retry"
 	KILL_CONNECTOR_JOB_URL  = SPECIFIC_JOB_URL + "/connector/%s/kill"
 	RETRY_CONNECTOR_JOB_URL = SPECIFIC_JOB_URL + "/connector/%s/retry"
+	RESCAN_JOB_URL          = SPECIFIC_JOB_URL + "/rescan"
+)
+
+// These represent comment endpoints URL
+const (
+	BASE_COMMENT_URL     = "/api/comments"
+	SPECIFIC_COMMENT_URL = BASE_COMMENT_URL + "/%d"
+	JOB_COMMENTS_URL     = BASE_COMMENT_URL + "?job_id=%d"
 )
 
 // These represent playbook endpoints URL
 const (
-	BASE_PLAYBOOK_URL = "/api/playbook"
+	BASE_PLAYBOOK_URL     = "/api/playbook"
+	SPECIFIC_PLAYBOOK_URL = BASE_PLAYBOOK_URL + "/%s"
+)
+
+// These represent pivot endpoints URL
+const (
+	PIVOT_CONFIG_URL          = "/api/pivot"
+	SPECIFIC_PIVOT_CONFIG_URL = PIVOT_CONFIG_URL + "/%s"
+	PIVOT_HEALTHCHECK_URL     = "/api/pivot/%s/healthcheck"
+	PIVOT_MAP_URL             = "/api/pivot_map"
 )
 
 // These represent analyzer endpoints URL
 const (
-	ANALYZER_CONFIG_URL      = "/api/analyzer"
-	ANALYZER_HEALTHCHECK_URL = "/api/analyzer/%s/healthcheck"
+	ANALYZER_CONFIG_URL          = "/api/analyzer"
+	SPECIFIC_ANALYZER_CONFIG_URL = ANALYZER_CONFIG_URL + "/%s"
+	ANALYZER_HEALTHCHECK_URL     = "/api/analyzer/%s/healthcheck"
+	ANALYZER_PULL_URL            = "/api/analyzer/%s/pull"
 )
 
 // These represent connector endpoints URL
 const (
-	CONNECTOR_CONFIG_URL      = "/api/connector"
-	CONNECTOR_HEALTHCHECK_URL = "/api/connector/%s/healthcheck"
+	CONNECTOR_CONFIG_URL          = "/api/connector"
+	SPECIFIC_CONNECTOR_CONFIG_URL = CONNECTOR_CONFIG_URL + "/%s"
+	CONNECTOR_HEALTHCHECK_URL     = "/api/connector/%s/healthcheck"
+)
+
+// These represent plugin config endpoints URL
+const (
+	PLUGIN_CONFIG_URL          = "/api/plugin-config"
+	SPECIFIC_PLUGIN_CONFIG_URL = PLUGIN_CONFIG_URL + "/%d"
+)
+
+// These represent visualizer endpoints URL
+const (
+	VISUALIZER_CONFIG_URL          = "/api/visualizer"
+	SPECIFIC_VISUALIZER_CONFIG_URL = VISUALIZER_CONFIG_URL + "/%s"
+	VISUALIZER_HEALTHCHECK_URL     = "/api/visualizer/%s/healthcheck"
+)
+
+// These represent ingestor endpoints URL
+const (
+	INGESTOR_CONFIG_URL          = "/api/ingestor"
+	SPECIFIC_INGESTOR_CONFIG_URL = INGESTOR_CONFIG_URL + "/%s"
+)
+
+// These represent investigation endpoints URL
+const (
+	BASE_INVESTIGATION_URL       = "/api/investigation"
+	SPECIFIC_INVESTIGATION_URL   = BASE_INVESTIGATION_URL + "/%d"
+	ADD_JOB_INVESTIGATION_URL    = SPECIFIC_INVESTIGATION_URL + "/add_job"
+	REMOVE_JOB_INVESTIGATION_URL = SPECIFIC_INVESTIGATION_URL + "/remove_job"
+	INVESTIGATION_TREE_URL       = SPECIFIC_INVESTIGATION_URL + "/tree"
 )
 
 // These represent analyze endpoints URL
@@ -45,6 +93,12 @@ const (
 	ANALYZE_MULTIPLE_FILES_URL       = "/api/analyze_multiple_files"
 )
 
+// These represent authentication endpoints URL
+const (
+	LOGIN_URL         = "/api/auth/login"
+	REFRESH_TOKEN_URL = "/api/auth/refresh"
+)
+
 // These represent me endpoints URL
 const (
 	BASE_ME_URL                         = "/api/me"
@@ -52,6 +106,13 @@ const (
 	ORGANIZATION_URL                    = BASE_ME_URL + "/organization"
 	INVITE_TO_ORGANIZATION_URL          = ORGANIZATION_URL + "/invite"
 	REMOVE_MEMBER_FROM_ORGANIZATION_URL = ORGANIZATION_URL + "/remove_member"
+	LEAVE_ORGANIZATION_URL              = ORGANIZATION_URL + "/leave"
+	PROMOTE_ADMIN_URL                   = ORGANIZATION_URL + "/promote_admin"
+	REMOVE_ADMIN_URL                    = ORGANIZATION_URL + "/remove_admin"
+	ORGANIZATION_INVITATIONS_URL        = ORGANIZATION_URL + "/invitations"
+	INVITATIONS_URL                     = BASE_ME_URL + "/invitations"
+	ACCEPT_INVITATION_URL               = INVITATIONS_URL + "/%d/accept"
+	DECLINE_INVITATION_URL              = INVITATIONS_URL + "/%d/decline"
 )
 
 // These represent REST API related constants
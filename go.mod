@@ -5,6 +5,7 @@ go 1.17
 require (
 	github.com/google/go-cmp v0.6.0
 	github.com/sirupsen/logrus v1.9.3
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require golang.org/x/sys v0.0.0-20220722155257-8c9f86f7a55f // indirect
@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"mime/multipart"
 	"os"
 	"path/filepath"
+	"strconv"
 
 	"github.com/khulnasoft/go-threatmatrix/constants"
 )
@@ -36,6 +39,14 @@ type ObservablePlaybookAnalysisParams struct {
 	PlaybookRequested        string `json:"playbook_requested"`
 }
 
+// MultipleObservablePlaybookAnalysisParams represents the fields needed to analyze multiple observables with a playbook.
+// Every observable is a [classification, name] pair, so each one can have a different classification.
+type MultipleObservablePlaybookAnalysisParams struct {
+	BasicAnalysisParams
+	Observables       [][]string `json:"observables"`
+	PlaybookRequested string     `json:"playbook_requested"`
+}
+
 // MultipleObservableAnalysisParams represents the fields needed to analyze multiple observables.
 type MultipleObservableAnalysisParams struct {
 	BasicAnalysisParams
@@ -60,6 +71,13 @@ type MultipleFileAnalysisParams struct {
 	Files []*os.File
 }
 
+// MultipleFilePlaybookAnalysisParams represents the fields needed to analyze multiple files with a playbook.
+type MultipleFilePlaybookAnalysisParams struct {
+	BasicAnalysisParams
+	PlaybookRequested string `json:"playbook_requested"`
+	Files             []*os.File
+}
+
 // AnalysisResponse represents a response returned by the API when you analyze an observable or file.
 type AnalysisResponse struct {
 	JobID              int      `json:"job_id"`
@@ -95,7 +113,7 @@ func (client *Client) CreateObservableAnalysis(ctx context.Context, params *Obse
 	}
 
 	analysisResponse := AnalysisResponse{}
-	successResp, err := client.newRequest(ctx, request)
+	successResp, err := client.newSubmissionRequest(ctx, request, 1)
 	if err != nil {
 		return nil, err
 	}
@@ -106,19 +124,48 @@ func (client *Client) CreateObservableAnalysis(ctx context.Context, params *Obse
 
 }
 
+// CreateObservablePlaybookAnalysis lets you analyze an observable with a playbook.
+//
+//	Endpoint: POST /api/playbook/analyze_multiple_observables
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/playbook/operation/playbook_analyze_multiple_observables_create
 func (client *Client) CreateObservablePlaybookAnalysis(ctx context.Context, params *ObservablePlaybookAnalysisParams) (*MultipleAnalysisResponse, error) {
+	multipleParams := &MultipleObservablePlaybookAnalysisParams{
+		BasicAnalysisParams: params.BasicAnalysisParams,
+		Observables:         [][]string{{params.ObservableClassification, params.ObservableName}},
+		PlaybookRequested:   params.PlaybookRequested,
+	}
+	return client.CreateMultipleObservablePlaybookAnalysis(ctx, multipleParams)
+}
+
+// CreateMultipleObservablePlaybookAnalysis lets you analyze multiple observables, each with its own classification, with a playbook.
+//
+//	Endpoint: POST /api/playbook/analyze_multiple_observables
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/playbook/operation/playbook_analyze_multiple_observables_create
+func (client *Client) CreateMultipleObservablePlaybookAnalysis(ctx context.Context, params *MultipleObservablePlaybookAnalysisParams) (*MultipleAnalysisResponse, error) {
+	for _, observable := range params.Observables {
+		if len(observable) != 2 {
+			return nil, fmt.Errorf("Observable %v should be a [classification, name] pair", observable)
+		}
+	}
 	requestUrl := client.options.Url + constants.ANALYZE_OBSERVABLE_PLAYBOOK_URL
 	method := "POST"
 	contentType := "application/json"
 	data := map[string]interface{}{
-		"observables":           [][]string{{params.ObservableClassification, params.ObservableName}},
+		"observables":           params.Observables,
 		"playbook_requested":    params.PlaybookRequested,
+		"analyzers_requested":   params.AnalyzersRequested,
+		"connectors_requested":  params.ConnectorsRequested,
 		"tags_labels":           params.TagsLabels,
 		"runtime_configuration": params.RuntimeConfiguration,
 		"tlp":                   params.Tlp.String(),
 	}
 
-	jsonData, _ := json.Marshal(data)
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
 
 	body := bytes.NewBuffer(jsonData)
 	request, err := client.buildRequest(ctx, method, contentType, body, requestUrl)
@@ -126,7 +173,7 @@ func (client *Client) CreateObservablePlaybookAnalysis(ctx context.Context, para
 		return nil, err
 	}
 	analysisResponse := MultipleAnalysisResponse{}
-	successResp, err := client.newRequest(ctx, request)
+	successResp, err := client.newSubmissionRequest(ctx, request, len(params.Observables))
 	if err != nil {
 		return nil, err
 	}
@@ -155,7 +202,7 @@ func (client *Client) CreateMultipleObservableAnalysis(ctx context.Context, para
 	}
 
 	multipleAnalysisResponse := MultipleAnalysisResponse{}
-	successResp, err := client.newRequest(ctx, request)
+	successResp, err := client.newSubmissionRequest(ctx, request, len(params.Observables))
 	if err != nil {
 		return nil, err
 	}
@@ -165,149 +212,124 @@ func (client *Client) CreateMultipleObservableAnalysis(ctx context.Context, para
 	return &multipleAnalysisResponse, nil
 }
 
-// CreateFileAnalysis lets you analyze a file.
-//
-//	Endpoint: POST /api/analyze_file
-//
-// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/analyze_file
-func (client *Client) CreateFileAnalysis(ctx context.Context, fileAnalysisParams *FileAnalysisParams) (*AnalysisResponse, error) {
-	requestUrl := client.options.Url + constants.ANALYZE_FILE_URL
-	// * Making the multiform data
+// writeAnalysisForm writes the BasicAnalysisParams fields, the requested playbook when set and the files into a multipart body,
+// every file being sent under fileField.
+// It returns the body and its content type.
+func writeAnalysisForm(params *BasicAnalysisParams, playbookRequested string, fileField string, files []*os.File) (*bytes.Buffer, string, error) {
+	if len(files) == 0 {
+		return nil, "", errors.New("At least one file is required")
+	}
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
+	// * Adding the user field, the server uses the authenticated user when it is not set
+	if params.User != 0 {
+		if err := writer.WriteField("user", strconv.Itoa(params.User)); err != nil {
+			return nil, "", err
+		}
+	}
 	// * Adding the TLP field
-	writeTlpError := writer.WriteField("tlp", fileAnalysisParams.Tlp.String())
-	if writeTlpError != nil {
-		return nil, writeTlpError
+	if err := writer.WriteField("tlp", params.Tlp.String()); err != nil {
+		return nil, "", err
 	}
 	// * Adding the runtimeconfiguration field
-	runTimeConfigurationJson, marshalError := json.Marshal(fileAnalysisParams.RuntimeConfiguration)
-	if marshalError != nil {
-		return nil, marshalError
+	runTimeConfigurationJson, err := json.Marshal(params.RuntimeConfiguration)
+	if err != nil {
+		return nil, "", err
 	}
-	runTimeConfigurationJsonString := string(runTimeConfigurationJson)
-	writeRuntimeError := writer.WriteField("runtime_configuration", runTimeConfigurationJsonString)
-	if writeRuntimeError != nil {
-		return nil, writeRuntimeError
+	if err := writer.WriteField("runtime_configuration", string(runTimeConfigurationJson)); err != nil {
+		return nil, "", err
 	}
-
-	// * Adding the requested analyzers
-	for _, analyzer := range fileAnalysisParams.AnalyzersRequested {
-		writeAnalyzerError := writer.WriteField("analyzers_requested", analyzer)
-		if writeAnalyzerError != nil {
-			return nil, writeAnalyzerError
+	// * Adding the Playbook field
+	if playbookRequested != "" {
+		if err := writer.WriteField("playbook_requested", playbookRequested); err != nil {
+			return nil, "", err
 		}
 	}
-
-	// * Adding the requested connectors
-	for _, connector := range fileAnalysisParams.ConnectorsRequested {
-		writeConnectorError := writer.WriteField("connectors_requested", connector)
-		if writeConnectorError != nil {
-			return nil, writeConnectorError
+	// * Adding the requested analyzers, connectors and the tag labels
+	listFields := []struct {
+		name   string
+		values []string
+	}{
+		{"analyzers_requested", params.AnalyzersRequested},
+		{"connectors_requested", params.ConnectorsRequested},
+		{"tags_labels", params.TagsLabels},
+	}
+	for _, listField := range listFields {
+		for _, value := range listField.values {
+			if err := writer.WriteField(listField.name, value); err != nil {
+				return nil, "", err
+			}
 		}
 	}
-
-	// * Adding the tag labels
-	for _, tagLabel := range fileAnalysisParams.TagsLabels {
-		writeTagLabelError := writer.WriteField("tags_labels", tagLabel)
-		if writeTagLabelError != nil {
-			return nil, writeTagLabelError
+	// * Adding the files!
+	for _, file := range files {
+		if file == nil {
+			return nil, "", errors.New("File cannot be nil")
+		}
+		filePart, err := writer.CreateFormFile(fileField, filepath.Base(file.Name()))
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := io.Copy(filePart, file); err != nil {
+			return nil, "", err
 		}
 	}
-
-	// * Adding the file!
-	filePart, _ := writer.CreateFormFile("file", filepath.Base(fileAnalysisParams.File.Name()))
-	_, writeFileError := io.Copy(filePart, fileAnalysisParams.File)
-	if writeFileError != nil {
-		writer.Close()
-		return nil, writeFileError
+	if err := writer.Close(); err != nil {
+		return nil, "", err
 	}
-	writer.Close()
+	return body, writer.FormDataContentType(), nil
+}
 
-	//* building the request!
-	contentType := writer.FormDataContentType()
+// sendAnalysisForm builds the multipart form of a file analysis, sends it to requestUrl and decodes the answer into analysisResponse.
+func (client *Client) sendAnalysisForm(ctx context.Context, requestUrl string, params *BasicAnalysisParams, playbookRequested string, fileField string, files []*os.File, analysisResponse interface{}) error {
+	body, contentType, err := writeAnalysisForm(params, playbookRequested, fileField, files)
+	if err != nil {
+		return err
+	}
 	method := "POST"
 	request, err := client.buildRequest(ctx, method, contentType, body, requestUrl)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	analysisResponse := AnalysisResponse{}
-	successResp, err := client.newRequest(ctx, request)
+	successResp, err := client.newSubmissionRequest(ctx, request, len(files))
 	if err != nil {
-		return nil, err
+		return err
 	}
-	if unmarshalError := json.Unmarshal(successResp.Data, &analysisResponse); unmarshalError != nil {
-		return nil, unmarshalError
+	if unmarshalError := json.Unmarshal(successResp.Data, analysisResponse); unmarshalError != nil {
+		return unmarshalError
 	}
-	return &analysisResponse, nil
+	return nil
 }
 
-func (client *Client) CreateFilePlaybookAnalysis(ctx context.Context, fileAnalysisParams *FilePlaybookAnalysisParams) (*MultipleAnalysisResponse, error) {
-	requestUrl := client.options.Url + constants.ANALYZE_FILE_PLAYBOOK_URL
-	// * Making the multiform data
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	// * Adding the TLP field
-	writeTlpError := writer.WriteField("tlp", fileAnalysisParams.Tlp.String())
-	if writeTlpError != nil {
-		return nil, writeTlpError
-	}
-	// * Adding the runtimeconfiguration field
-	runTimeConfigurationJson, marshalError := json.Marshal(fileAnalysisParams.RuntimeConfiguration)
-	if marshalError != nil {
-		return nil, marshalError
-	}
-	runTimeConfigurationJsonString := string(runTimeConfigurationJson)
-	writeRuntimeError := writer.WriteField("runtime_configuration", runTimeConfigurationJsonString)
-	if writeRuntimeError != nil {
-		return nil, writeRuntimeError
-	}
-
-	// * Adding the Playbook field
-	writePlaybookError := writer.WriteField("playbook_requested", fileAnalysisParams.PlaybookRequested)
-	if writePlaybookError != nil {
-		return nil, writeTlpError
-	}
-
-	// * Adding the tag labels
-	for _, tagLabel := range fileAnalysisParams.TagsLabels {
-		writeTagLabelError := writer.WriteField("tags_labels", tagLabel)
-		if writeTagLabelError != nil {
-			return nil, writeTagLabelError
-		}
-	}
-
-	// * Adding the file!
-	filePart, _ := writer.CreateFormFile("files", filepath.Base(fileAnalysisParams.File.Name()))
-	_, writeFileError := io.Copy(filePart, fileAnalysisParams.File)
-	if writeFileError != nil {
-		writer.Close()
-		return nil, writeFileError
-	}
-	writer.Close()
-	//* building the request!
-	contentType := writer.FormDataContentType()
-	method := "POST"
-	request, err := client.buildRequest(ctx, method, contentType, body, requestUrl)
-	if err != nil {
-		return nil, err
-	}
-
-	analysisResponse := MultipleAnalysisResponse{}
-	successResp, err := client.newRequest(ctx, request)
+// CreateFileAnalysis lets you analyze a file.
+//
+//	Endpoint: POST /api/analyze_file
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/analyze_file
+func (client *Client) CreateFileAnalysis(ctx context.Context, fileAnalysisParams *FileAnalysisParams) (*AnalysisResponse, error) {
+	requestUrl := client.options.Url + constants.ANALYZE_FILE_URL
+	analysisResponse := AnalysisResponse{}
+	err := client.sendAnalysisForm(ctx, requestUrl, &fileAnalysisParams.BasicAnalysisParams, "", "file", []*os.File{fileAnalysisParams.File}, &analysisResponse)
 	if err != nil {
 		return nil, err
 	}
-
-	if unmarshalError := json.Unmarshal(successResp.Data, &analysisResponse); unmarshalError != nil {
-		return nil, unmarshalError
-	}
-
 	return &analysisResponse, nil
 }
 
+// CreateFilePlaybookAnalysis lets you analyze a file with a playbook.
+//
+//	Endpoint: POST /api/playbook/analyze_multiple_files
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/playbook/operation/playbook_analyze_multiple_files_create
+func (client *Client) CreateFilePlaybookAnalysis(ctx context.Context, fileAnalysisParams *FilePlaybookAnalysisParams) (*MultipleAnalysisResponse, error) {
+	return client.CreateMultipleFilePlaybookAnalysis(ctx, &MultipleFilePlaybookAnalysisParams{
+		BasicAnalysisParams: fileAnalysisParams.BasicAnalysisParams,
+		PlaybookRequested:   fileAnalysisParams.PlaybookRequested,
+		Files:               []*os.File{fileAnalysisParams.File},
+	})
+}
+
 // CreateMultipleFileAnalysis lets you analyze multiple files.
 //
 //	Endpoint: POST /api/analyze_mutliple_files
@@ -315,76 +337,28 @@ func (client *Client) CreateFilePlaybookAnalysis(ctx context.Context, fileAnalys
 // ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/analyze_multiple_files
 func (client *Client) CreateMultipleFileAnalysis(ctx context.Context, fileAnalysisParams *MultipleFileAnalysisParams) (*MultipleAnalysisResponse, error) {
 	requestUrl := client.options.Url + constants.ANALYZE_MULTIPLE_FILES_URL
-	// * Making the multiform data
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	// * Adding the TLP field
-	writeTlpError := writer.WriteField("tlp", fileAnalysisParams.Tlp.String())
-	if writeTlpError != nil {
-		return nil, writeTlpError
-	}
-	// * Adding the runtimeconfiguration field
-	runTimeConfigurationJson, marshalError := json.Marshal(fileAnalysisParams.RuntimeConfiguration)
-	if marshalError != nil {
-		return nil, marshalError
-	}
-	runTimeConfigurationJsonString := string(runTimeConfigurationJson)
-	writeRuntimeError := writer.WriteField("runtime_configuration", runTimeConfigurationJsonString)
-	if writeRuntimeError != nil {
-		return nil, writeRuntimeError
-	}
-
-	// * Adding the requested analyzers
-	for _, analyzer := range fileAnalysisParams.AnalyzersRequested {
-		writeAnalyzerError := writer.WriteField("analyzers_requested", analyzer)
-		if writeAnalyzerError != nil {
-			return nil, writeAnalyzerError
-		}
-	}
-
-	// * Adding the requested connectors
-	for _, connector := range fileAnalysisParams.ConnectorsRequested {
-		writeConnectorError := writer.WriteField("connectors_requested", connector)
-		if writeConnectorError != nil {
-			return nil, writeConnectorError
-		}
-	}
-
-	// * Adding the tag labels
-	for _, tagLabel := range fileAnalysisParams.TagsLabels {
-		writeTagLabelError := writer.WriteField("tags_labels", tagLabel)
-		if writeTagLabelError != nil {
-			return nil, writeTagLabelError
-		}
-	}
-
-	// * Adding the files!
-	for _, file := range fileAnalysisParams.Files {
-		filePart, _ := writer.CreateFormFile("files", filepath.Base(file.Name()))
-		_, writeFileError := io.Copy(filePart, file)
-		if writeFileError != nil {
-			writer.Close()
-			return nil, writeFileError
-		}
-	}
-	writer.Close()
-
-	//* building the request!
-	contentType := writer.FormDataContentType()
-	method := "POST"
-	request, err := client.buildRequest(ctx, method, contentType, body, requestUrl)
+	multipleAnalysisResponse := MultipleAnalysisResponse{}
+	err := client.sendAnalysisForm(ctx, requestUrl, &fileAnalysisParams.BasicAnalysisParams, "", "files", fileAnalysisParams.Files, &multipleAnalysisResponse)
 	if err != nil {
 		return nil, err
 	}
+	return &multipleAnalysisResponse, nil
+}
 
+// CreateMultipleFilePlaybookAnalysis lets you analyze multiple files with a playbook.
+//
+//	Endpoint: POST /api/playbook/analyze_multiple_files
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/playbook/operation/playbook_analyze_multiple_files_create
+func (client *Client) CreateMultipleFilePlaybookAnalysis(ctx context.Context, fileAnalysisParams *MultipleFilePlaybookAnalysisParams) (*MultipleAnalysisResponse, error) {
+	if fileAnalysisParams.PlaybookRequested == "" {
+		return nil, errors.New("PlaybookRequested cannot be empty")
+	}
+	requestUrl := client.options.Url + constants.ANALYZE_FILE_PLAYBOOK_URL
 	multipleAnalysisResponse := MultipleAnalysisResponse{}
-	successResp, err := client.newRequest(ctx, request)
+	err := client.sendAnalysisForm(ctx, requestUrl, &fileAnalysisParams.BasicAnalysisParams, fileAnalysisParams.PlaybookRequested, "files", fileAnalysisParams.Files, &multipleAnalysisResponse)
 	if err != nil {
 		return nil, err
 	}
-	if unmarshalError := json.Unmarshal(successResp.Data, &multipleAnalysisResponse); unmarshalError != nil {
-		return nil, unmarshalError
-	}
 	return &multipleAnalysisResponse, nil
 }
@@ -39,23 +39,11 @@ type AnalyzerService struct {
 //
 // ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/get_analyzer_configs
 func (analyzerService *AnalyzerService) GetConfigs(ctx context.Context) (*[]AnalyzerConfig, error) {
-	requestUrl := analyzerService.client.options.Url + constants.ANALYZER_CONFIG_URL
-	contentType := constants.ContentTypeJSON
-	method := http.MethodGet
-	request, err := analyzerService.client.buildRequest(ctx, method, contentType, nil, requestUrl)
+	analyzerConfigurationResponse, err := analyzerService.fetchConfigs(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	successResp, err := analyzerService.client.newRequest(ctx, request)
-	if err != nil {
-		return nil, err
-	}
-	analyzerConfigurationResponse := map[string]AnalyzerConfig{}
-	if unmarshalError := json.Unmarshal(successResp.Data, &analyzerConfigurationResponse); unmarshalError != nil {
-		return nil, unmarshalError
-	}
-
 	analyzerNames := make([]string, 0)
 	// *getting all the analyzer key names!
 	for analyzerName := range analyzerConfigurationResponse {
@@ -63,7 +51,7 @@ func (analyzerService *AnalyzerService) GetConfigs(ctx context.Context) (*[]Anal
 	}
 	// * sorting them alphabetically
 	sort.Strings(analyzerNames)
-	var analyzerConfigurationList []AnalyzerConfig
+	analyzerConfigurationList := []AnalyzerConfig{}
 	for _, analyzerName := range analyzerNames {
 		analyzerConfig := analyzerConfigurationResponse[analyzerName]
 		analyzerConfigurationList = append(analyzerConfigurationList, analyzerConfig)
@@ -71,6 +59,28 @@ func (analyzerService *AnalyzerService) GetConfigs(ctx context.Context) (*[]Anal
 	return &analyzerConfigurationList, nil
 }
 
+// fetchConfigs downloads every analyzer configuration indexed by name and refreshes the client's ConfigCache.
+func (analyzerService *AnalyzerService) fetchConfigs(ctx context.Context) (map[string]AnalyzerConfig, error) {
+	requestUrl := analyzerService.client.options.Url + constants.ANALYZER_CONFIG_URL
+	contentType := constants.ContentTypeJSON
+	method := http.MethodGet
+	request, err := analyzerService.client.buildRequest(ctx, method, contentType, nil, requestUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	successResp, err := analyzerService.client.newRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	analyzerConfigurationResponse := map[string]AnalyzerConfig{}
+	if unmarshalError := json.Unmarshal(successResp.Data, &analyzerConfigurationResponse); unmarshalError != nil {
+		return nil, unmarshalError
+	}
+	analyzerService.client.ConfigCache.setAnalyzers(analyzerConfigurationResponse)
+	return analyzerConfigurationResponse, nil
+}
+
 // HealthCheck checks if the specified analyzer is up and running
 //
 //	Endpoint: GET /api/analyzer/{NameOfAnalyzer}/healthcheck
@@ -95,3 +105,124 @@ func (analyzerService *AnalyzerService) HealthCheck(ctx context.Context, analyze
 	}
 	return status.Status, nil
 }
+
+// Get fetches the configuration of the specified analyzer.
+//
+//	Endpoint: GET /api/analyzer/{NameOfAnalyzer}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/analyzer/operation/analyzer_retrieve
+func (analyzerService *AnalyzerService) Get(ctx context.Context, analyzerName string) (*AnalyzerConfig, error) {
+	analyzerConfig := AnalyzerConfig{}
+	if err := analyzerService.client.getPluginConfig(ctx, constants.SPECIFIC_ANALYZER_CONFIG_URL, analyzerName, &analyzerConfig); err != nil {
+		return nil, err
+	}
+	return &analyzerConfig, nil
+}
+
+// Enable lets you enable an analyzer.
+//
+//	Endpoint: PATCH /api/analyzer/{NameOfAnalyzer}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/analyzer/operation/analyzer_partial_update
+func (analyzerService *AnalyzerService) Enable(ctx context.Context, analyzerName string) (*AnalyzerConfig, error) {
+	analyzerConfig := AnalyzerConfig{}
+	if err := analyzerService.client.setPluginDisabled(ctx, constants.SPECIFIC_ANALYZER_CONFIG_URL, analyzerName, false, &analyzerConfig); err != nil {
+		return nil, err
+	}
+	return &analyzerConfig, nil
+}
+
+// Disable lets you disable an analyzer.
+//
+//	Endpoint: PATCH /api/analyzer/{NameOfAnalyzer}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/analyzer/operation/analyzer_partial_update
+func (analyzerService *AnalyzerService) Disable(ctx context.Context, analyzerName string) (*AnalyzerConfig, error) {
+	analyzerConfig := AnalyzerConfig{}
+	if err := analyzerService.client.setPluginDisabled(ctx, constants.SPECIFIC_ANALYZER_CONFIG_URL, analyzerName, true, &analyzerConfig); err != nil {
+		return nil, err
+	}
+	return &analyzerConfig, nil
+}
+
+// SetParams sets organization-level values of the analyzer's params, the ones your organization already overrides are updated.
+// Every param must be declared by the analyzer, the configuration is then fetched again so its Verification is up to date.
+//
+//	Endpoint: POST /api/plugin-config
+//	Endpoint: PATCH /api/plugin-config/{id}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/plugin-config
+func (analyzerService *AnalyzerService) SetParams(ctx context.Context, analyzerName string, params map[string]interface{}) (*AnalyzerConfig, error) {
+	analyzerConfig, err := analyzerService.Get(ctx, analyzerName)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPluginAttributes(analyzerName, "params", analyzerConfig.paramNames(), sortedKeys(params)); err != nil {
+		return nil, err
+	}
+	if err := analyzerService.client.OrganizationService.setValues(ctx, PluginTypeAnalyzer, analyzerName, params, nil); err != nil {
+		return nil, err
+	}
+	return analyzerService.Get(ctx, analyzerName)
+}
+
+// SetSecrets sets organization-level values of the analyzer's secrets, the ones your organization already overrides are updated.
+// Every secret must be declared by the analyzer, the configuration is then fetched again so its Verification is up to date.
+// The values are never logged and are redacted from the returned errors.
+//
+//	Endpoint: POST /api/plugin-config
+//	Endpoint: PATCH /api/plugin-config/{id}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/plugin-config
+func (analyzerService *AnalyzerService) SetSecrets(ctx context.Context, analyzerName string, secrets map[string]SecretValue) (*AnalyzerConfig, error) {
+	analyzerConfig, err := analyzerService.Get(ctx, analyzerName)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPluginAttributes(analyzerName, "secrets", analyzerConfig.secretNames(), secretKeys(secrets)); err != nil {
+		return nil, err
+	}
+	values, plainValues := revealSecrets(secrets)
+	if err := analyzerService.client.OrganizationService.setValues(ctx, PluginTypeAnalyzer, analyzerName, values, plainValues); err != nil {
+		return nil, err
+	}
+	return analyzerService.Get(ctx, analyzerName)
+}
+
+// RefreshVerification fetches the current Verification status of an analyzer, e.g after its secrets were set.
+//
+//	Endpoint: GET /api/analyzer/{NameOfAnalyzer}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/analyzer/operation/analyzer_retrieve
+func (analyzerService *AnalyzerService) RefreshVerification(ctx context.Context, analyzerName string) (*VerificationType, error) {
+	analyzerConfig, err := analyzerService.Get(ctx, analyzerName)
+	if err != nil {
+		return nil, err
+	}
+	return &analyzerConfig.Verification, nil
+}
+
+// Pull updates the docker image or the rules of the specified analyzer.
+//
+//	Endpoint: POST /api/analyzer/{NameOfAnalyzer}/pull
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/analyzer/operation/analyzer_pull_create
+func (analyzerService *AnalyzerService) Pull(ctx context.Context, analyzerName string) (bool, error) {
+	route := analyzerService.client.options.Url + constants.ANALYZER_PULL_URL
+	requestUrl := fmt.Sprintf(route, analyzerName)
+	contentType := constants.ContentTypeJSON
+	method := http.MethodPost
+	request, err := analyzerService.client.buildRequest(ctx, method, contentType, nil, requestUrl)
+	if err != nil {
+		return false, err
+	}
+	status := StatusResponse{}
+	successResp, err := analyzerService.client.newRequest(ctx, request)
+	if err != nil {
+		return false, err
+	}
+	if unmarshalError := json.Unmarshal(successResp.Data, &status); unmarshalError != nil {
+		return false, unmarshalError
+	}
+	return status.Status, nil
+}
@@ -0,0 +1,77 @@
+package gothreatmatrix
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// PullParams represents the fields needed to select the analyzers updated by AnalyzerService.PullAll.
+type PullParams struct {
+	// Filter selects the analyzers to pull, by default every analyzer is tried and ThreatMatrix tells which ones it can update.
+	Filter func(analyzerConfig *AnalyzerConfig) bool
+	// IncludeDisabled also pulls the disabled analyzers.
+	IncludeDisabled bool
+	// Concurrency is the maximum number of pulls in flight, it defaults to 5.
+	Concurrency int
+}
+
+// PullResult represents the outcome of pulling a single analyzer.
+type PullResult struct {
+	Name    string `json:"name"`
+	Success bool   `json:"success"`
+	// Unsupported is set when ThreatMatrix refused the pull because the analyzer has nothing to update, Error holds its answer.
+	Unsupported bool `json:"unsupported"`
+	// DurationMs is how long the pull took, in milliseconds.
+	DurationMs float64 `json:"duration_ms"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// PullAll pulls every selected analyzer concurrently and returns the outcome per analyzer, sorted by name.
+// The analyzers ThreatMatrix can't update are reported as Unsupported.
+// The error is only set when the analyzer configurations could not be fetched.
+//
+//	Endpoint: POST /api/analyzer/{NameOfAnalyzer}/pull
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/analyzer/operation/analyzer_pull_create
+func (analyzerService *AnalyzerService) PullAll(ctx context.Context, params *PullParams) ([]PullResult, error) {
+	if params == nil {
+		params = &PullParams{}
+	}
+	analyzerConfigs, err := analyzerService.fetchConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	analyzerNames := []string{}
+	for name, analyzerConfig := range analyzerConfigs {
+		if analyzerConfig.Disabled && !params.IncludeDisabled {
+			continue
+		}
+		if params.Filter == nil || params.Filter(&analyzerConfig) {
+			analyzerNames = append(analyzerNames, name)
+		}
+	}
+	sort.Strings(analyzerNames)
+
+	// * every goroutine writes its own index
+	results := make([]PullResult, len(analyzerNames))
+	started := runConcurrently(ctx, len(analyzerNames), params.Concurrency, func(ctx context.Context, index int) {
+		result := PullResult{Name: analyzerNames[index]}
+		startedAt := time.Now()
+		success, err := analyzerService.Pull(ctx, result.Name)
+		result.DurationMs = float64(time.Since(startedAt)) / float64(time.Millisecond)
+		result.Success = success && err == nil
+		if err != nil {
+			result.Error = err.Error()
+			threatMatrixError := &Error{}
+			result.Unsupported = errors.As(err, &threatMatrixError) && threatMatrixError.StatusCode == http.StatusBadRequest
+		}
+		results[index] = result
+	})
+	for index := started; index < len(analyzerNames); index++ {
+		results[index] = PullResult{Name: analyzerNames[index], Error: ctx.Err().Error()}
+	}
+	return results, nil
+}
@@ -0,0 +1,269 @@
+package gothreatmatrix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/khulnasoft/go-threatmatrix/constants"
+)
+
+// defaultBulkConcurrency is the number of requests in flight when BulkJobParams.Concurrency is not set.
+const defaultBulkConcurrency = 5
+
+// ReportStatusFailed is the status of an analyzer or connector Report that failed.
+const ReportStatusFailed = "FAILED"
+
+// JobFilter reports whether a job listed by JobService.ListAll should be selected.
+type JobFilter func(job *JobList) bool
+
+// BulkJobParams represents the fields needed to select jobs for a bulk operation.
+// The selected jobs are the union of JobIDs and every job matching Filter.
+type BulkJobParams struct {
+	JobIDs []uint64
+	Filter JobFilter
+	// Concurrency is the maximum number of requests in flight, it defaults to 5.
+	// Once ctx is done no request is started, the jobs left get the context error.
+	Concurrency int
+}
+
+// BulkResult represents the outcome of a bulk operation on a single job.
+type BulkResult struct {
+	Success bool
+	Error   error
+}
+
+// RetryFailedResult represents the outcome of JobService.RetryFailedAnalyzers on a single job.
+type RetryFailedResult struct {
+	// Analyzers maps the name of every failed analyzer to the outcome of its retry.
+	Analyzers map[string]BulkResult
+	// Error is set when the job itself could not be fetched.
+	Error error
+}
+
+// JobQuery represents the filters ThreatMatrix applies to the job list, empty fields are not sent.
+type JobQuery struct {
+	// ObservableName selects the jobs whose observable name contains it, case-insensitively.
+	ObservableName string
+	// Md5 selects the jobs whose md5 contains it, case-insensitively.
+	Md5 string
+	// Username selects the jobs whose user's username contains it, case-insensitively.
+	Username string
+	// TagLabel selects the jobs carrying a tag with this label.
+	TagLabel string
+}
+
+// values returns the query parameters of the job list.
+func (jobQuery *JobQuery) values() url.Values {
+	values := url.Values{}
+	if jobQuery == nil {
+		return values
+	}
+	if jobQuery.ObservableName != "" {
+		values.Set("observable_name", jobQuery.ObservableName)
+	}
+	if jobQuery.Md5 != "" {
+		values.Set("md5", jobQuery.Md5)
+	}
+	if jobQuery.Username != "" {
+		values.Set("user", jobQuery.Username)
+	}
+	if jobQuery.TagLabel != "" {
+		values.Set("tags__label", jobQuery.TagLabel)
+	}
+	return values
+}
+
+// ListAll fetches every page of jobs in your ThreatMatrix instance.
+//
+//	Endpoint: GET /api/jobs?page={page}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/jobs/operation/jobs_list
+func (jobService *JobService) ListAll(ctx context.Context) ([]JobList, error) {
+	return jobService.Search(ctx, nil)
+}
+
+// Search fetches every page of the jobs matching jobQuery, the filtering is done by ThreatMatrix.
+//
+//	Endpoint: GET /api/jobs?page={page}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/jobs/operation/jobs_list
+func (jobService *JobService) Search(ctx context.Context, jobQuery *JobQuery) ([]JobList, error) {
+	jobs := []JobList{}
+	values := jobQuery.values()
+	for page := 1; ; page++ {
+		values.Set("page", strconv.Itoa(page))
+		requestUrl := fmt.Sprintf("%s%s?%s", jobService.client.options.Url, constants.BASE_JOB_URL, values.Encode())
+		contentType := constants.ContentTypeJSON
+		method := http.MethodGet
+		request, err := jobService.client.buildRequest(ctx, method, contentType, nil, requestUrl)
+		if err != nil {
+			return nil, err
+		}
+		successResp, err := jobService.client.newRequest(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		jobList := JobListResponse{}
+		if unmarshalError := json.Unmarshal(successResp.Data, &jobList); unmarshalError != nil {
+			return nil, unmarshalError
+		}
+		jobs = append(jobs, jobList.Results...)
+		if page >= jobList.TotalPages || len(jobList.Results) == 0 {
+			return jobs, nil
+		}
+	}
+}
+
+// selectJobIDs resolves the job IDs selected by BulkJobParams without duplicates.
+func (jobService *JobService) selectJobIDs(ctx context.Context, params *BulkJobParams) ([]uint64, error) {
+	seen := map[uint64]bool{}
+	jobIds := []uint64{}
+	for _, jobId := range params.JobIDs {
+		if !seen[jobId] {
+			seen[jobId] = true
+			jobIds = append(jobIds, jobId)
+		}
+	}
+	if params.Filter == nil {
+		return jobIds, nil
+	}
+	jobs, err := jobService.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for index := range jobs {
+		jobId := uint64(jobs[index].ID)
+		if !seen[jobId] && params.Filter(&jobs[index]) {
+			seen[jobId] = true
+			jobIds = append(jobIds, jobId)
+		}
+	}
+	return jobIds, nil
+}
+
+// runConcurrently calls action for every index below count with at most concurrency calls in flight.
+// Once ctx is done no new call is started, it returns how many were started: the indexes from there on were skipped.
+func runConcurrently(ctx context.Context, count int, concurrency int, action func(ctx context.Context, index int)) int {
+	if concurrency <= 0 {
+		concurrency = defaultBulkConcurrency
+	}
+	semaphore := make(chan struct{}, concurrency)
+	var waitGroup sync.WaitGroup
+	defer waitGroup.Wait()
+	for index := 0; index < count; index++ {
+		// * checked first so a free slot never wins over a done context
+		if ctx.Err() != nil {
+			return index
+		}
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			return index
+		}
+		waitGroup.Add(1)
+		go func(index int) {
+			defer waitGroup.Done()
+			defer func() { <-semaphore }()
+			action(ctx, index)
+		}(index)
+	}
+	return count
+}
+
+// runBulk calls action on every job ID with at most concurrency calls in flight.
+// It returns the job IDs skipped because ctx was done.
+func runBulk(ctx context.Context, jobIds []uint64, concurrency int, action func(ctx context.Context, jobId uint64)) []uint64 {
+	started := runConcurrently(ctx, len(jobIds), concurrency, func(ctx context.Context, index int) {
+		action(ctx, jobIds[index])
+	})
+	return jobIds[started:]
+}
+
+// bulk runs a single job action over every selected job and collects a BulkResult per job.
+func (jobService *JobService) bulk(ctx context.Context, params *BulkJobParams, action func(ctx context.Context, jobId uint64) (bool, error)) (map[uint64]BulkResult, error) {
+	jobIds, err := jobService.selectJobIDs(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[uint64]BulkResult, len(jobIds))
+	var mutex sync.Mutex
+	skipped := runBulk(ctx, jobIds, params.Concurrency, func(ctx context.Context, jobId uint64) {
+		success, err := action(ctx, jobId)
+		mutex.Lock()
+		results[jobId] = BulkResult{Success: success, Error: err}
+		mutex.Unlock()
+	})
+	for _, jobId := range skipped {
+		results[jobId] = BulkResult{Error: ctx.Err()}
+	}
+	return results, nil
+}
+
+// BulkKill stops every selected job and returns the outcome per job ID.
+// The error is only set when the jobs could not be selected.
+func (jobService *JobService) BulkKill(ctx context.Context, params *BulkJobParams) (map[uint64]BulkResult, error) {
+	return jobService.bulk(ctx, params, jobService.Kill)
+}
+
+// BulkDelete removes every selected job and returns the outcome per job ID.
+// The error is only set when the jobs could not be selected.
+func (jobService *JobService) BulkDelete(ctx context.Context, params *BulkJobParams) (map[uint64]BulkResult, error) {
+	return jobService.bulk(ctx, params, jobService.Delete)
+}
+
+// BulkRetryAnalyzer re-runs the given analyzer on every selected job and returns the outcome per job ID.
+// The error is only set when the jobs could not be selected.
+func (jobService *JobService) BulkRetryAnalyzer(ctx context.Context, params *BulkJobParams, analyzerName string) (map[uint64]BulkResult, error) {
+	return jobService.bulk(ctx, params, func(ctx context.Context, jobId uint64) (bool, error) {
+		return jobService.RetryAnalyzer(ctx, jobId, analyzerName)
+	})
+}
+
+// BulkRetryConnector re-runs the given connector on every selected job and returns the outcome per job ID.
+// The error is only set when the jobs could not be selected.
+func (jobService *JobService) BulkRetryConnector(ctx context.Context, params *BulkJobParams, connectorName string) (map[uint64]BulkResult, error) {
+	return jobService.bulk(ctx, params, func(ctx context.Context, jobId uint64) (bool, error) {
+		return jobService.RetryConnector(ctx, jobId, connectorName)
+	})
+}
+
+// RetryFailedAnalyzers fetches every selected job and re-runs each analyzer whose Report.Status is FAILED.
+// The error is only set when the jobs could not be selected.
+func (jobService *JobService) RetryFailedAnalyzers(ctx context.Context, params *BulkJobParams) (map[uint64]RetryFailedResult, error) {
+	jobIds, err := jobService.selectJobIDs(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[uint64]RetryFailedResult, len(jobIds))
+	var mutex sync.Mutex
+	skipped := runBulk(ctx, jobIds, params.Concurrency, func(ctx context.Context, jobId uint64) {
+		result := RetryFailedResult{
+			Analyzers: map[string]BulkResult{},
+		}
+		job, err := jobService.Get(ctx, jobId)
+		if err != nil {
+			result.Error = err
+		} else {
+			for _, report := range job.AnalyzerReports {
+				if !strings.EqualFold(report.Status, ReportStatusFailed) {
+					continue
+				}
+				success, err := jobService.RetryAnalyzer(ctx, jobId, report.Name)
+				result.Analyzers[report.Name] = BulkResult{Success: success, Error: err}
+			}
+		}
+		mutex.Lock()
+		results[jobId] = result
+		mutex.Unlock()
+	})
+	for _, jobId := range skipped {
+		results[jobId] = RetryFailedResult{Analyzers: map[string]BulkResult{}, Error: ctx.Err()}
+	}
+	return results, nil
+}
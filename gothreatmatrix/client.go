@@ -51,19 +51,30 @@ type ClientOptions struct {
 	Certificate string `json:"certificate"`
 	// Timeout is in seconds
 	Timeout uint64 `json:"timeout"`
+	// CredentialProvider supplies the API token instead of Token when set, e.g to rotate it.
+	CredentialProvider CredentialProvider `json:"-"`
 }
 
 // Client handles all the communication with your ThreatMatrix instance.
 type Client struct {
-	options          *ClientOptions
-	client           *http.Client
-	TagService       *TagService
-	JobService       *JobService
-	PlaybookService  *PlaybookService
-	AnalyzerService  *AnalyzerService
-	ConnectorService *ConnectorService
-	UserService      *UserService
-	Logger           *Logger
+	options              *ClientOptions
+	client               *http.Client
+	credentials          *credentialStore
+	TagService           *TagService
+	JobService           *JobService
+	PlaybookService      *PlaybookService
+	AnalyzerService      *AnalyzerService
+	ConnectorService     *ConnectorService
+	PivotService         *PivotService
+	VisualizerService    *VisualizerService
+	IngestorService      *IngestorService
+	InvestigationService *InvestigationService
+	UserService          *UserService
+	OrganizationService  *OrganizationService
+	CommentService       *CommentService
+	ConfigCache          *ConfigCache
+	QuotaGuard           *QuotaGuard
+	Logger               *Logger
 }
 
 // TLP represents an enum for the TLP attribute used in ThreatMatrix's REST API.
@@ -151,6 +162,9 @@ func NewClient(options *ClientOptions, httpClient *http.Client, loggerParams *Lo
 	client := Client{
 		options: options,
 		client:  httpClient,
+		credentials: &credentialStore{
+			provider: options.CredentialProvider,
+		},
 	}
 
 	// Adding the services
@@ -169,9 +183,35 @@ func NewClient(options *ClientOptions, httpClient *http.Client, loggerParams *Lo
 	client.ConnectorService = &ConnectorService{
 		client: &client,
 	}
+	client.PivotService = &PivotService{
+		client: &client,
+	}
+	client.VisualizerService = &VisualizerService{
+		client: &client,
+	}
+	client.IngestorService = &IngestorService{
+		client: &client,
+	}
+	client.InvestigationService = &InvestigationService{
+		client: &client,
+	}
 	client.UserService = &UserService{
 		client: &client,
 	}
+	client.OrganizationService = &OrganizationService{
+		client: &client,
+	}
+	client.CommentService = &CommentService{
+		client: &client,
+	}
+
+	// Sharing the plugin configurations between the services
+	client.ConfigCache = &ConfigCache{}
+
+	// Guarding the submissions once QuotaGuard.Enable is called
+	client.QuotaGuard = &QuotaGuard{
+		client: &client,
+	}
 
 	// configuring the logger!
 	client.Logger = &Logger{}
@@ -205,9 +245,15 @@ func (client *Client) buildRequest(ctx context.Context, method string, contentTy
 	if err != nil {
 		return nil, err
 	}
-	request.Header.Set("Content-Type", contentType)
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
 
-	tokenString := fmt.Sprintf("token %s", client.options.Token)
+	token, err := client.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tokenString := fmt.Sprintf("token %s", token)
 
 	request.Header.Set("Authorization", tokenString)
 	return request, nil
@@ -236,6 +282,9 @@ func (client *Client) newRequest(ctx context.Context, request *http.Request) (*s
 		return nil, threatMatrixError
 	}
 
+	if statusCode == http.StatusUnauthorized {
+		client.credentialsRejected(request)
+	}
 	if statusCode < http.StatusOK || statusCode >= http.StatusBadRequest {
 		errorMessage := string(msgBytes)
 		threatMatrixError := newError(statusCode, errorMessage, response)
@@ -249,3 +298,36 @@ func (client *Client) newRequest(ctx context.Context, request *http.Request) (*s
 
 	return &sucessResp, nil
 }
+
+// newStreamRequest is used for making requests whose response body is consumed by the caller.
+// The caller must close the body of the returned response.
+func (client *Client) newStreamRequest(ctx context.Context, request *http.Request) (*http.Response, error) {
+	response, err := client.client.Do(request)
+	// Checking for context errors such as reaching the deadline and/or Timeout
+	if err != nil {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+		return nil, err
+	}
+
+	statusCode := response.StatusCode
+	if statusCode == http.StatusUnauthorized {
+		client.credentialsRejected(request)
+	}
+	if statusCode < http.StatusOK || statusCode >= http.StatusBadRequest {
+		defer response.Body.Close()
+		msgBytes, err := io.ReadAll(response.Body)
+		if err != nil {
+			errorMessage := fmt.Sprintf("Could not convert JSON response. Status code: %d", statusCode)
+			threatMatrixError := newError(statusCode, errorMessage, response)
+			return nil, threatMatrixError
+		}
+		threatMatrixError := newError(statusCode, string(msgBytes), response)
+		return nil, threatMatrixError
+	}
+
+	return response, nil
+}
@@ -0,0 +1,210 @@
+package gothreatmatrix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// These represent the environment variables read by NewClientFromEnv and LoadClientOptions.
+const (
+	EnvUrl         = "THREATMATRIX_URL"
+	EnvToken       = "THREATMATRIX_TOKEN"
+	EnvTokenFile   = "THREATMATRIX_TOKEN_FILE"
+	EnvCertificate = "THREATMATRIX_CERTIFICATE"
+	EnvTimeout     = "THREATMATRIX_TIMEOUT"
+	EnvProfile     = "THREATMATRIX_PROFILE"
+	EnvConfig      = "THREATMATRIX_CONFIG"
+)
+
+// These represent the prefixes of a secret referencing where its value is instead of holding it.
+const (
+	SecretFromEnv  = "env:"
+	SecretFromFile = "file:"
+)
+
+// DefaultProfile is the profile used when neither the caller, THREATMATRIX_PROFILE nor the profiles file name one.
+const DefaultProfile = "default"
+
+// Profile represents the ClientOptions of one ThreatMatrix instance in a profiles file.
+// Token can hold the token itself, "env:NAME" to read it from an environment variable
+// or "file:PATH" to read it from a file, re-read whenever it changes.
+type Profile struct {
+	Url         string `json:"url"`
+	Token       string `json:"token"`
+	Certificate string `json:"certificate"`
+	// Timeout is in seconds
+	Timeout uint64 `json:"timeout"`
+}
+
+// ProfilesFile represents a file describing several ThreatMatrix instances, e.g prod, staging and lab.
+// It is written in JSON or, when its extension is .yaml or .yml, in YAML.
+type ProfilesFile struct {
+	// Default is the profile used when none is asked for.
+	Default  string             `json:"default"`
+	Profiles map[string]Profile `json:"profiles"`
+}
+
+// DefaultProfilesPath returns where the profiles file is looked for when THREATMATRIX_CONFIG is not set:
+// threatmatrix/profiles.json in the user configuration directory.
+func DefaultProfilesPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "threatmatrix", "profiles.json"), nil
+}
+
+// LoadProfilesFile reads a profiles file.
+func LoadProfilesFile(filePath string) (*ProfilesFile, error) {
+	fileBytes, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	extension := strings.ToLower(filepath.Ext(filePath))
+	if extension == ".yaml" || extension == ".yml" {
+		// * going through JSON so that the json tags are the only field names
+		var document interface{}
+		if err := yaml.Unmarshal(fileBytes, &document); err != nil {
+			return nil, fmt.Errorf("%s: %w", filePath, err)
+		}
+		if fileBytes, err = json.Marshal(document); err != nil {
+			return nil, fmt.Errorf("%s: %w", filePath, err)
+		}
+	}
+	profilesFile := ProfilesFile{}
+	if unmarshalError := json.Unmarshal(fileBytes, &profilesFile); unmarshalError != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, unmarshalError)
+	}
+	return &profilesFile, nil
+}
+
+// resolveToken turns a token, or a reference to it, into ClientOptions.
+// References become a CredentialProvider so the token is read when needed, it is read once here to fail early.
+func resolveToken(token string, options *ClientOptions) error {
+	var provider CredentialProvider
+	switch {
+	case strings.HasPrefix(token, SecretFromEnv):
+		provider = EnvCredentials(strings.TrimPrefix(token, SecretFromEnv))
+	case strings.HasPrefix(token, SecretFromFile):
+		provider = NewFileCredentials(strings.TrimPrefix(token, SecretFromFile))
+	default:
+		options.Token = token
+		options.CredentialProvider = nil
+		return nil
+	}
+	if _, err := provider.Token(context.Background()); err != nil {
+		return err
+	}
+	options.Token = ""
+	options.CredentialProvider = provider
+	return nil
+}
+
+// LoadClientOptions builds ClientOptions from a profiles file then from the environment, the environment taking precedence.
+//
+// The profiles file is filePath, else THREATMATRIX_CONFIG, else DefaultProfilesPath; only an explicitly named file must exist.
+// The profile is profile, else THREATMATRIX_PROFILE, else the default of the file, else "default"; only an explicitly named profile must exist.
+// Then THREATMATRIX_URL, THREATMATRIX_TOKEN, THREATMATRIX_TOKEN_FILE, THREATMATRIX_CERTIFICATE and THREATMATRIX_TIMEOUT override the profile when set.
+func LoadClientOptions(filePath string, profile string) (*ClientOptions, error) {
+	explicitFile := filePath != "" || os.Getenv(EnvConfig) != ""
+	if filePath == "" {
+		filePath = os.Getenv(EnvConfig)
+	}
+	if filePath == "" {
+		defaultPath, err := DefaultProfilesPath()
+		if err == nil {
+			filePath = defaultPath
+		}
+	}
+	explicitProfile := profile != "" || os.Getenv(EnvProfile) != ""
+	if profile == "" {
+		profile = os.Getenv(EnvProfile)
+	}
+
+	options := &ClientOptions{}
+	if filePath != "" {
+		profilesFile, err := LoadProfilesFile(filePath)
+		switch {
+		case err == nil:
+			if profile == "" {
+				profile = profilesFile.Default
+			}
+			if profile == "" {
+				profile = DefaultProfile
+			}
+			fileProfile, ok := profilesFile.Profiles[profile]
+			if !ok && explicitProfile {
+				return nil, fmt.Errorf("%s: unknown profile %s", filePath, profile)
+			}
+			if ok {
+				options.Url = fileProfile.Url
+				options.Certificate = fileProfile.Certificate
+				options.Timeout = fileProfile.Timeout
+				if err := resolveToken(fileProfile.Token, options); err != nil {
+					return nil, fmt.Errorf("profile %s: %w", profile, err)
+				}
+			}
+		case explicitFile || !errors.Is(err, os.ErrNotExist):
+			return nil, err
+		}
+	}
+
+	// * the environment takes precedence over the profiles file
+	if url := os.Getenv(EnvUrl); url != "" {
+		options.Url = url
+	}
+	if token := os.Getenv(EnvToken); token != "" {
+		if err := resolveToken(token, options); err != nil {
+			return nil, fmt.Errorf("%s: %w", EnvToken, err)
+		}
+	}
+	if tokenFile := os.Getenv(EnvTokenFile); tokenFile != "" {
+		if err := resolveToken(SecretFromFile+tokenFile, options); err != nil {
+			return nil, fmt.Errorf("%s: %w", EnvTokenFile, err)
+		}
+	}
+	if certificate := os.Getenv(EnvCertificate); certificate != "" {
+		options.Certificate = certificate
+	}
+	if timeout := os.Getenv(EnvTimeout); timeout != "" {
+		seconds, err := strconv.ParseUint(timeout, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", EnvTimeout, err)
+		}
+		options.Timeout = seconds
+	}
+
+	if options.Url == "" {
+		return nil, fmt.Errorf("No ThreatMatrix URL: set %s or a profile url", EnvUrl)
+	}
+	if options.Token == "" && options.CredentialProvider == nil {
+		return nil, fmt.Errorf("No ThreatMatrix token: set %s, %s or a profile token", EnvToken, EnvTokenFile)
+	}
+	return options, nil
+}
+
+// NewClientFromEnv lets you create a new Client configured by the environment, on top of the profile it selects.
+// See LoadClientOptions for the variables read and their precedence.
+func NewClientFromEnv(httpClient *http.Client, loggerParams *LoggerParams) (*Client, error) {
+	return NewClientFromProfile("", "", httpClient, loggerParams)
+}
+
+// NewClientFromProfile lets you create a new Client through a named profile of a profiles file, empty values select the defaults.
+// The environment still takes precedence over the file, see LoadClientOptions.
+func NewClientFromProfile(filePath string, profile string, httpClient *http.Client, loggerParams *LoggerParams) (*Client, error) {
+	options, err := LoadClientOptions(filePath, profile)
+	if err != nil {
+		return nil, err
+	}
+	threatMatrixClient := NewClient(options, httpClient, loggerParams)
+	return &threatMatrixClient, nil
+}
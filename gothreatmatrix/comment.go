@@ -0,0 +1,155 @@
+package gothreatmatrix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/khulnasoft/go-threatmatrix/constants"
+)
+
+// CommentParams represents the fields needed for creating a comment on a job.
+type CommentParams struct {
+	JobID   uint64 `json:"job_id"`
+	Content string `json:"content"`
+}
+
+// Comment represents a comment left on an ThreatMatrix job.
+type Comment struct {
+	ID        uint64      `json:"id"`
+	Content   string      `json:"content"`
+	User      UserDetails `json:"user"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// CommentService handles communication with comment related methods of ThreatMatrix API.
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/comments
+type CommentService struct {
+	client *Client
+}
+
+// checkCommentID is used to check if a comment ID is valid (id should be greater than zero).
+func checkCommentID(id uint64) error {
+	if id > 0 {
+		return nil
+	}
+	return errors.New("Comment ID cannot be 0")
+}
+
+// List fetches all the comments of a specific job through its job ID.
+//
+//	Endpoint: GET /api/comments?job_id={jobID}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/comments/operation/comments_list
+func (commentService *CommentService) List(ctx context.Context, jobId uint64) (*[]Comment, error) {
+	route := commentService.client.options.Url + constants.JOB_COMMENTS_URL
+	requestUrl := fmt.Sprintf(route, jobId)
+	contentType := constants.ContentTypeJSON
+	method := http.MethodGet
+	request, err := commentService.client.buildRequest(ctx, method, contentType, nil, requestUrl)
+	if err != nil {
+		return nil, err
+	}
+	successResp, err := commentService.client.newRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	commentList := []Comment{}
+	if unmarshalError := json.Unmarshal(successResp.Data, &commentList); unmarshalError != nil {
+		return nil, unmarshalError
+	}
+	return &commentList, nil
+}
+
+// Create lets you post a new comment on a job by passing CommentParams.
+//
+//	Endpoint: POST /api/comments
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/comments/operation/comments_create
+func (commentService *CommentService) Create(ctx context.Context, commentParams *CommentParams) (*Comment, error) {
+	if commentParams.JobID == 0 {
+		return nil, errors.New("Job ID cannot be 0")
+	}
+	requestUrl := commentService.client.options.Url + constants.BASE_COMMENT_URL
+	commentJson, err := json.Marshal(commentParams)
+	if err != nil {
+		return nil, err
+	}
+	contentType := constants.ContentTypeJSON
+	method := http.MethodPost
+	body := bytes.NewBuffer(commentJson)
+	request, err := commentService.client.buildRequest(ctx, method, contentType, body, requestUrl)
+	if err != nil {
+		return nil, err
+	}
+	successResp, err := commentService.client.newRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	createdComment := Comment{}
+	if unmarshalError := json.Unmarshal(successResp.Data, &createdComment); unmarshalError != nil {
+		return nil, unmarshalError
+	}
+	return &createdComment, nil
+}
+
+// Get fetches a specific comment through its comment ID.
+//
+//	Endpoint: GET /api/comments/{id}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/comments/operation/comments_retrieve
+func (commentService *CommentService) Get(ctx context.Context, commentId uint64) (*Comment, error) {
+	if err := checkCommentID(commentId); err != nil {
+		return nil, err
+	}
+	route := commentService.client.options.Url + constants.SPECIFIC_COMMENT_URL
+	requestUrl := fmt.Sprintf(route, commentId)
+	contentType := constants.ContentTypeJSON
+	method := http.MethodGet
+	request, err := commentService.client.buildRequest(ctx, method, contentType, nil, requestUrl)
+	if err != nil {
+		return nil, err
+	}
+	successResp, err := commentService.client.newRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	comment := Comment{}
+	if unmarshalError := json.Unmarshal(successResp.Data, &comment); unmarshalError != nil {
+		return nil, unmarshalError
+	}
+	return &comment, nil
+}
+
+// Delete removes the given comment from its job.
+//
+//	Endpoint: DELETE /api/comments/{id}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/comments/operation/comments_destroy
+func (commentService *CommentService) Delete(ctx context.Context, commentId uint64) (bool, error) {
+	if err := checkCommentID(commentId); err != nil {
+		return false, err
+	}
+	route := commentService.client.options.Url + constants.SPECIFIC_COMMENT_URL
+	requestUrl := fmt.Sprintf(route, commentId)
+	contentType := constants.ContentTypeJSON
+	method := http.MethodDelete
+	request, err := commentService.client.buildRequest(ctx, method, contentType, nil, requestUrl)
+	if err != nil {
+		return false, err
+	}
+	successResp, err := commentService.client.newRequest(ctx, request)
+	if err != nil {
+		return false, err
+	}
+	if successResp.StatusCode == http.StatusNoContent {
+		return true, nil
+	}
+	return false, nil
+}
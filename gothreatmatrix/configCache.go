@@ -0,0 +1,147 @@
+package gothreatmatrix
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultConfigCacheTTL is how long the plugin configurations are cached when ConfigCache.TTL is not set.
+const DefaultConfigCacheTTL = 5 * time.Minute
+
+// ConfigCache keeps the analyzer and connector configurations of your ThreatMatrix instance for TTL,
+// so repeated lookups such as playbook validations don't download every configuration again.
+// It is shared by every service of a Client.
+type ConfigCache struct {
+	TTL          time.Duration
+	mutex        sync.Mutex
+	analyzers    map[string]AnalyzerConfig
+	analyzersAt  time.Time
+	connectors   map[string]ConnectorConfig
+	connectorsAt time.Time
+}
+
+// ttl returns the configured TTL or DefaultConfigCacheTTL.
+func (configCache *ConfigCache) ttl() time.Duration {
+	if configCache.TTL <= 0 {
+		return DefaultConfigCacheTTL
+	}
+	return configCache.TTL
+}
+
+// Invalidate drops every cached configuration.
+func (configCache *ConfigCache) Invalidate() {
+	configCache.mutex.Lock()
+	defer configCache.mutex.Unlock()
+	configCache.analyzers = nil
+	configCache.connectors = nil
+}
+
+// copyBaseConfiguration returns a deep copy of the fields shared by analyzer and connector configurations.
+func copyBaseConfiguration(baseConfiguration BaseConfigurationType) BaseConfigurationType {
+	if baseConfiguration.Secrets != nil {
+		secrets := make(map[string]Secret, len(baseConfiguration.Secrets))
+		for name, secret := range baseConfiguration.Secrets {
+			secrets[name] = secret
+		}
+		baseConfiguration.Secrets = secrets
+	}
+	if baseConfiguration.Params != nil {
+		params := make(map[string]Parameter, len(baseConfiguration.Params))
+		for name, parameter := range baseConfiguration.Params {
+			parameter.Value = copyValue(parameter.Value)
+			parameter.Type = copyValue(parameter.Type)
+			params[name] = parameter
+		}
+		baseConfiguration.Params = params
+	}
+	baseConfiguration.Verification.MissingSecrets = copyStrings(baseConfiguration.Verification.MissingSecrets)
+	return baseConfiguration
+}
+
+// copyAnalyzers returns a deep copy of analyzer configurations so callers never share the cached ones.
+func copyAnalyzers(analyzers map[string]AnalyzerConfig) map[string]AnalyzerConfig {
+	analyzersCopy := make(map[string]AnalyzerConfig, len(analyzers))
+	for name, analyzerConfig := range analyzers {
+		analyzerConfig.BaseConfigurationType = copyBaseConfiguration(analyzerConfig.BaseConfigurationType)
+		analyzerConfig.SupportedFiletypes = copyStrings(analyzerConfig.SupportedFiletypes)
+		analyzerConfig.NotSupportedFiletypes = copyStrings(analyzerConfig.NotSupportedFiletypes)
+		analyzerConfig.ObservableSupported = copyStrings(analyzerConfig.ObservableSupported)
+		analyzersCopy[name] = analyzerConfig
+	}
+	return analyzersCopy
+}
+
+// copyConnectors returns a deep copy of connector configurations so callers never share the cached ones.
+func copyConnectors(connectors map[string]ConnectorConfig) map[string]ConnectorConfig {
+	connectorsCopy := make(map[string]ConnectorConfig, len(connectors))
+	for name, connectorConfig := range connectors {
+		connectorConfig.BaseConfigurationType = copyBaseConfiguration(connectorConfig.BaseConfigurationType)
+		connectorsCopy[name] = connectorConfig
+	}
+	return connectorsCopy
+}
+
+// setAnalyzers stores a copy of freshly downloaded analyzer configurations.
+func (configCache *ConfigCache) setAnalyzers(analyzers map[string]AnalyzerConfig) {
+	configCache.mutex.Lock()
+	defer configCache.mutex.Unlock()
+	configCache.analyzers = copyAnalyzers(analyzers)
+	configCache.analyzersAt = time.Now()
+}
+
+// setConnectors stores a copy of freshly downloaded connector configurations.
+func (configCache *ConfigCache) setConnectors(connectors map[string]ConnectorConfig) {
+	configCache.mutex.Lock()
+	defer configCache.mutex.Unlock()
+	configCache.connectors = copyConnectors(connectors)
+	configCache.connectorsAt = time.Now()
+}
+
+// cachedAnalyzers returns a copy of the analyzer configurations if they have not expired yet.
+func (configCache *ConfigCache) cachedAnalyzers() (map[string]AnalyzerConfig, bool) {
+	configCache.mutex.Lock()
+	defer configCache.mutex.Unlock()
+	if configCache.analyzers == nil || time.Since(configCache.analyzersAt) >= configCache.ttl() {
+		return nil, false
+	}
+	return copyAnalyzers(configCache.analyzers), true
+}
+
+// cachedConnectors returns a copy of the connector configurations if they have not expired yet.
+func (configCache *ConfigCache) cachedConnectors() (map[string]ConnectorConfig, bool) {
+	configCache.mutex.Lock()
+	defer configCache.mutex.Unlock()
+	if configCache.connectors == nil || time.Since(configCache.connectorsAt) >= configCache.ttl() {
+		return nil, false
+	}
+	return copyConnectors(configCache.connectors), true
+}
+
+// GetConfigsByName returns every analyzer configuration indexed by name.
+// The configurations come from the client's ConfigCache and are downloaded again once they expire,
+// the returned map and configurations are the caller's own.
+//
+//	Endpoint: GET /api/analyzer
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/analyzer
+func (analyzerService *AnalyzerService) GetConfigsByName(ctx context.Context) (map[string]AnalyzerConfig, error) {
+	if analyzers, ok := analyzerService.client.ConfigCache.cachedAnalyzers(); ok {
+		return analyzers, nil
+	}
+	return analyzerService.fetchConfigs(ctx)
+}
+
+// GetConfigsByName returns every connector configuration indexed by name.
+// The configurations come from the client's ConfigCache and are downloaded again once they expire,
+// the returned map and configurations are the caller's own.
+//
+//	Endpoint: GET /api/connector
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/connector
+func (connectorService *ConnectorService) GetConfigsByName(ctx context.Context) (map[string]ConnectorConfig, error) {
+	if connectors, ok := connectorService.client.ConfigCache.cachedConnectors(); ok {
+		return connectors, nil
+	}
+	return connectorService.fetchConfigs(ctx)
+}
@@ -31,25 +31,13 @@ type ConnectorService struct {
 //
 // ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/get_connector_configs
 func (connectorService *ConnectorService) GetConfigs(ctx context.Context) (*[]ConnectorConfig, error) {
-	requestUrl := connectorService.client.options.Url + constants.CONNECTOR_CONFIG_URL
-	contentType := constants.ContentTypeJSON
-	method := http.MethodGet
-	request, err := connectorService.client.buildRequest(ctx, method, contentType, nil, requestUrl)
-	if err != nil {
-		return nil, err
-	}
-
-	successResp, err := connectorService.client.newRequest(ctx, request)
+	connectorConfigurationResponse, err := connectorService.fetchConfigs(ctx)
 	if err != nil {
 		return nil, err
 	}
-	connectorConfigurationResponse := map[string]ConnectorConfig{}
-	if unmarshalError := json.Unmarshal(successResp.Data, &connectorConfigurationResponse); unmarshalError != nil {
-		return nil, unmarshalError
-	}
 
 	connectorNames := make([]string, 0)
-	// *getting all the analyzer key names!
+	// *getting all the connector key names!
 	for connectorName := range connectorConfigurationResponse {
 		connectorNames = append(connectorNames, connectorName)
 	}
@@ -63,6 +51,28 @@ func (connectorService *ConnectorService) GetConfigs(ctx context.Context) (*[]Co
 	return &connectorConfigurationList, nil
 }
 
+// fetchConfigs downloads every connector configuration indexed by name and refreshes the client's ConfigCache.
+func (connectorService *ConnectorService) fetchConfigs(ctx context.Context) (map[string]ConnectorConfig, error) {
+	requestUrl := connectorService.client.options.Url + constants.CONNECTOR_CONFIG_URL
+	contentType := constants.ContentTypeJSON
+	method := http.MethodGet
+	request, err := connectorService.client.buildRequest(ctx, method, contentType, nil, requestUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	successResp, err := connectorService.client.newRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	connectorConfigurationResponse := map[string]ConnectorConfig{}
+	if unmarshalError := json.Unmarshal(successResp.Data, &connectorConfigurationResponse); unmarshalError != nil {
+		return nil, unmarshalError
+	}
+	connectorService.client.ConfigCache.setConnectors(connectorConfigurationResponse)
+	return connectorConfigurationResponse, nil
+}
+
 // HealthCheck checks if the specified connector is up and running
 //
 //	Endpoint: GET /api/connector/{NameOfConnector}/healthcheck
@@ -87,3 +97,99 @@ func (connectorService *ConnectorService) HealthCheck(ctx context.Context, conne
 	}
 	return status.Status, nil
 }
+
+// Get fetches the configuration of the specified connector.
+//
+//	Endpoint: GET /api/connector/{NameOfConnector}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/connector/operation/connector_retrieve
+func (connectorService *ConnectorService) Get(ctx context.Context, connectorName string) (*ConnectorConfig, error) {
+	connectorConfig := ConnectorConfig{}
+	if err := connectorService.client.getPluginConfig(ctx, constants.SPECIFIC_CONNECTOR_CONFIG_URL, connectorName, &connectorConfig); err != nil {
+		return nil, err
+	}
+	return &connectorConfig, nil
+}
+
+// Enable lets you enable a connector.
+//
+//	Endpoint: PATCH /api/connector/{NameOfConnector}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/connector/operation/connector_partial_update
+func (connectorService *ConnectorService) Enable(ctx context.Context, connectorName string) (*ConnectorConfig, error) {
+	connectorConfig := ConnectorConfig{}
+	if err := connectorService.client.setPluginDisabled(ctx, constants.SPECIFIC_CONNECTOR_CONFIG_URL, connectorName, false, &connectorConfig); err != nil {
+		return nil, err
+	}
+	return &connectorConfig, nil
+}
+
+// Disable lets you disable a connector.
+//
+//	Endpoint: PATCH /api/connector/{NameOfConnector}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/connector/operation/connector_partial_update
+func (connectorService *ConnectorService) Disable(ctx context.Context, connectorName string) (*ConnectorConfig, error) {
+	connectorConfig := ConnectorConfig{}
+	if err := connectorService.client.setPluginDisabled(ctx, constants.SPECIFIC_CONNECTOR_CONFIG_URL, connectorName, true, &connectorConfig); err != nil {
+		return nil, err
+	}
+	return &connectorConfig, nil
+}
+
+// SetParams sets organization-level values of the connector's params, the ones your organization already overrides are updated.
+// Every param must be declared by the connector, the configuration is then fetched again so its Verification is up to date.
+//
+//	Endpoint: POST /api/plugin-config
+//	Endpoint: PATCH /api/plugin-config/{id}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/plugin-config
+func (connectorService *ConnectorService) SetParams(ctx context.Context, connectorName string, params map[string]interface{}) (*ConnectorConfig, error) {
+	connectorConfig, err := connectorService.Get(ctx, connectorName)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPluginAttributes(connectorName, "params", connectorConfig.paramNames(), sortedKeys(params)); err != nil {
+		return nil, err
+	}
+	if err := connectorService.client.OrganizationService.setValues(ctx, PluginTypeConnector, connectorName, params, nil); err != nil {
+		return nil, err
+	}
+	return connectorService.Get(ctx, connectorName)
+}
+
+// SetSecrets sets organization-level values of the connector's secrets, the ones your organization already overrides are updated.
+// Every secret must be declared by the connector, the configuration is then fetched again so its Verification is up to date.
+// The values are never logged and are redacted from the returned errors.
+//
+//	Endpoint: POST /api/plugin-config
+//	Endpoint: PATCH /api/plugin-config/{id}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/plugin-config
+func (connectorService *ConnectorService) SetSecrets(ctx context.Context, connectorName string, secrets map[string]SecretValue) (*ConnectorConfig, error) {
+	connectorConfig, err := connectorService.Get(ctx, connectorName)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPluginAttributes(connectorName, "secrets", connectorConfig.secretNames(), secretKeys(secrets)); err != nil {
+		return nil, err
+	}
+	values, plainValues := revealSecrets(secrets)
+	if err := connectorService.client.OrganizationService.setValues(ctx, PluginTypeConnector, connectorName, values, plainValues); err != nil {
+		return nil, err
+	}
+	return connectorService.Get(ctx, connectorName)
+}
+
+// RefreshVerification fetches the current Verification status of a connector, e.g after its secrets were set.
+//
+//	Endpoint: GET /api/connector/{NameOfConnector}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/connector/operation/connector_retrieve
+func (connectorService *ConnectorService) RefreshVerification(ctx context.Context, connectorName string) (*VerificationType, error) {
+	connectorConfig, err := connectorService.Get(ctx, connectorName)
+	if err != nil {
+		return nil, err
+	}
+	return &connectorConfig.Verification, nil
+}
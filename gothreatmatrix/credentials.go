@@ -0,0 +1,296 @@
+package gothreatmatrix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/khulnasoft/go-threatmatrix/constants"
+)
+
+// DefaultSessionRefreshBefore is how long before its expiry a session token is refreshed when SessionCredentialsOptions.RefreshBefore is not set.
+const DefaultSessionRefreshBefore = time.Minute
+
+// CredentialProvider represents a source of the API token sent with every request.
+// Token is called once per request so a provider can rotate its token at any time,
+// requests already in flight keep the token they were built with. Implementations must be safe for concurrent use.
+type CredentialProvider interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// credentialInvalidator is implemented by the providers that must drop their token once ThreatMatrix rejected it.
+// The rejected token is passed so a token rotated in the meantime is kept.
+type credentialInvalidator interface {
+	Invalidate(rejectedToken string)
+}
+
+// CredentialFunc lets you use an ordinary function as a CredentialProvider, e.g to fetch the token from a vault.
+type CredentialFunc func(ctx context.Context) (string, error)
+
+// Token calls the function.
+func (credentialFunc CredentialFunc) Token(ctx context.Context) (string, error) {
+	return credentialFunc(ctx)
+}
+
+// StaticCredentials represents a token that never changes.
+type StaticCredentials struct {
+	token SecretValue
+}
+
+// NewStaticCredentials returns a CredentialProvider always sending the given token.
+func NewStaticCredentials(token string) *StaticCredentials {
+	return &StaticCredentials{token: SecretValue(token)}
+}
+
+// Token returns the token.
+func (staticCredentials *StaticCredentials) Token(ctx context.Context) (string, error) {
+	return staticCredentials.token.Reveal(), nil
+}
+
+// EnvCredentials represents the name of an environment variable holding the token, it is read for every request.
+type EnvCredentials string
+
+// Token reads the environment variable.
+func (envCredentials EnvCredentials) Token(ctx context.Context) (string, error) {
+	token := strings.TrimSpace(os.Getenv(string(envCredentials)))
+	if token == "" {
+		return "", fmt.Errorf("The environment variable %s is not set", string(envCredentials))
+	}
+	return token, nil
+}
+
+// FileCredentials represents a file holding the token, it is read again whenever it changes so the token can be rotated on disk.
+type FileCredentials struct {
+	path    string
+	mutex   sync.Mutex
+	token   SecretValue
+	modTime time.Time
+	size    int64
+}
+
+// NewFileCredentials returns a CredentialProvider reading the token from the given file.
+func NewFileCredentials(path string) *FileCredentials {
+	return &FileCredentials{path: path}
+}
+
+// Token returns the content of the file, trimmed, reading it again when its modification time or size changed.
+func (fileCredentials *FileCredentials) Token(ctx context.Context) (string, error) {
+	fileCredentials.mutex.Lock()
+	defer fileCredentials.mutex.Unlock()
+	info, err := os.Stat(fileCredentials.path)
+	if err != nil {
+		return "", err
+	}
+	if fileCredentials.token != "" && info.ModTime().Equal(fileCredentials.modTime) && info.Size() == fileCredentials.size {
+		return fileCredentials.token.Reveal(), nil
+	}
+	content, err := os.ReadFile(fileCredentials.path)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", fmt.Errorf("%s is empty", fileCredentials.path)
+	}
+	fileCredentials.token = SecretValue(token)
+	fileCredentials.modTime = info.ModTime()
+	fileCredentials.size = info.Size()
+	return token, nil
+}
+
+// Invalidate forces the file to be read again on the next request, unless the token was rotated since rejectedToken was read.
+func (fileCredentials *FileCredentials) Invalidate(rejectedToken string) {
+	fileCredentials.mutex.Lock()
+	defer fileCredentials.mutex.Unlock()
+	if fileCredentials.token.Reveal() == rejectedToken {
+		fileCredentials.token = ""
+	}
+}
+
+// SessionCredentialsOptions represents the fields needed to log in to ThreatMatrix with a username and a password.
+type SessionCredentialsOptions struct {
+	Url      string
+	Username string
+	Password SecretValue
+	// HttpClient sends the login requests, it defaults to a client with a 10 seconds timeout.
+	HttpClient *http.Client
+	// RefreshBefore is how long before its expiry the session token is refreshed, it defaults to 1 minute.
+	RefreshBefore time.Duration
+}
+
+// SessionCredentials represents a session token obtained by logging in, refreshed before it expires.
+// When the refresh fails, or ThreatMatrix rejected the token, it logs in again.
+type SessionCredentials struct {
+	options SessionCredentialsOptions
+	mutex   sync.Mutex
+	token   SecretValue
+	expiry  time.Time
+}
+
+// sessionToken represents the answer of the login and refresh endpoints.
+type sessionToken struct {
+	Token  string    `json:"token"`
+	Expiry time.Time `json:"expiry"`
+}
+
+// NewSessionCredentials returns a CredentialProvider logging in with the given username and password.
+func NewSessionCredentials(options *SessionCredentialsOptions) (*SessionCredentials, error) {
+	if options.Url == "" || options.Username == "" || options.Password == "" {
+		return nil, errors.New("Url, Username and Password are required")
+	}
+	sessionOptions := *options
+	if sessionOptions.HttpClient == nil {
+		sessionOptions.HttpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if sessionOptions.RefreshBefore <= 0 {
+		sessionOptions.RefreshBefore = DefaultSessionRefreshBefore
+	}
+	return &SessionCredentials{options: sessionOptions}, nil
+}
+
+// Token returns the session token, logging in or refreshing it first when needed.
+//
+//	Endpoint: POST /api/auth/login
+//	Endpoint: POST /api/auth/refresh
+func (sessionCredentials *SessionCredentials) Token(ctx context.Context) (string, error) {
+	sessionCredentials.mutex.Lock()
+	defer sessionCredentials.mutex.Unlock()
+	now := time.Now()
+	if sessionCredentials.token != "" {
+		if sessionCredentials.expiry.IsZero() || now.Before(sessionCredentials.expiry.Add(-sessionCredentials.options.RefreshBefore)) {
+			return sessionCredentials.token.Reveal(), nil
+		}
+		if now.Before(sessionCredentials.expiry) {
+			if err := sessionCredentials.refresh(ctx); err == nil {
+				return sessionCredentials.token.Reveal(), nil
+			}
+		}
+	}
+	if err := sessionCredentials.login(ctx); err != nil {
+		return "", err
+	}
+	return sessionCredentials.token.Reveal(), nil
+}
+
+// Invalidate drops the session token so the next request logs in again, unless the session changed since rejectedToken was obtained.
+func (sessionCredentials *SessionCredentials) Invalidate(rejectedToken string) {
+	sessionCredentials.mutex.Lock()
+	defer sessionCredentials.mutex.Unlock()
+	if sessionCredentials.token.Reveal() == rejectedToken {
+		sessionCredentials.token = ""
+	}
+}
+
+// login obtains a new session token, the mutex must be held.
+func (sessionCredentials *SessionCredentials) login(ctx context.Context) error {
+	credentials := map[string]string{
+		"username": sessionCredentials.options.Username,
+		"password": sessionCredentials.options.Password.Reveal(),
+	}
+	credentialsJson, err := json.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+	session, err := sessionCredentials.send(ctx, constants.LOGIN_URL, "", bytes.NewBuffer(credentialsJson))
+	if err != nil {
+		return redactSecrets(err, []string{sessionCredentials.options.Password.Reveal()})
+	}
+	if session.Token == "" {
+		return errors.New("ThreatMatrix did not return a session token")
+	}
+	sessionCredentials.token = SecretValue(session.Token)
+	sessionCredentials.expiry = session.Expiry
+	return nil
+}
+
+// refresh extends the expiry of the current session token, the mutex must be held.
+func (sessionCredentials *SessionCredentials) refresh(ctx context.Context) error {
+	session, err := sessionCredentials.send(ctx, constants.REFRESH_TOKEN_URL, sessionCredentials.token.Reveal(), nil)
+	if err != nil {
+		return err
+	}
+	if session.Token != "" {
+		sessionCredentials.token = SecretValue(session.Token)
+	}
+	sessionCredentials.expiry = session.Expiry
+	return nil
+}
+
+// send posts to an authentication endpoint, token is sent when set.
+func (sessionCredentials *SessionCredentials) send(ctx context.Context, route string, token string, body io.Reader) (*sessionToken, error) {
+	requestUrl := sessionCredentials.options.Url + route
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, requestUrl, body)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", constants.ContentTypeJSON)
+	if token != "" {
+		request.Header.Set("Authorization", fmt.Sprintf("token %s", token))
+	}
+	response, err := sessionCredentials.options.HttpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	msgBytes, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusBadRequest {
+		return nil, newError(response.StatusCode, string(msgBytes), response)
+	}
+	session := sessionToken{}
+	if unmarshalError := json.Unmarshal(msgBytes, &session); unmarshalError != nil {
+		return nil, unmarshalError
+	}
+	return &session, nil
+}
+
+// credentialStore holds the CredentialProvider of a Client so it can be swapped while requests are in flight.
+type credentialStore struct {
+	mutex    sync.RWMutex
+	provider CredentialProvider
+}
+
+// SetCredentialProvider replaces the source of the API token, the requests already built keep their token.
+// A nil provider falls back to ClientOptions.Token.
+func (client *Client) SetCredentialProvider(provider CredentialProvider) {
+	client.credentials.mutex.Lock()
+	defer client.credentials.mutex.Unlock()
+	client.credentials.provider = provider
+}
+
+// credentialProvider returns the current CredentialProvider, nil when ClientOptions.Token is used.
+func (client *Client) credentialProvider() CredentialProvider {
+	client.credentials.mutex.RLock()
+	defer client.credentials.mutex.RUnlock()
+	return client.credentials.provider
+}
+
+// token returns the API token to send with a request.
+func (client *Client) token(ctx context.Context) (string, error) {
+	provider := client.credentialProvider()
+	if provider == nil {
+		return client.options.Token, nil
+	}
+	token, err := provider.Token(ctx)
+	if err != nil {
+		return "", fmt.Errorf("Could not get the API token: %w", err)
+	}
+	return token, nil
+}
+
+// credentialsRejected lets the CredentialProvider drop the token of a request ThreatMatrix answered 401 to.
+func (client *Client) credentialsRejected(request *http.Request) {
+	if invalidator, ok := client.credentialProvider().(credentialInvalidator); ok {
+		invalidator.Invalidate(strings.TrimPrefix(request.Header.Get("Authorization"), "token "))
+	}
+}
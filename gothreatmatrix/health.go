@@ -0,0 +1,162 @@
+package gothreatmatrix
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// These represent the plugin types, HealthReport only reports analyzers and connectors.
+const (
+	PluginTypeAnalyzer  = "analyzer"
+	PluginTypeConnector = "connector"
+	PluginTypeIngestor  = "ingestor"
+)
+
+// HealthReportOptions represents the optional fields of a health check sweep.
+type HealthReportOptions struct {
+	// Concurrency is the maximum number of health checks in flight, it defaults to 5.
+	Concurrency int
+}
+
+// PluginHealth represents the health of a single analyzer or connector.
+type PluginHealth struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Healthy bool   `json:"healthy"`
+	// LatencyMs is how long the health check took, in milliseconds.
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+	// Misconfigured flags an enabled plugin whose Verification is not Configured or that misses secrets.
+	Misconfigured     bool     `json:"misconfigured"`
+	MissingSecrets    []string `json:"missing_secrets,omitempty"`
+	VerificationError string   `json:"verification_error,omitempty"`
+}
+
+// HealthReport represents the outcome of a health check sweep over every analyzer and connector.
+type HealthReport struct {
+	CheckedAt time.Time `json:"checked_at"`
+	// DurationMs is how long the whole sweep took, in milliseconds.
+	DurationMs float64 `json:"duration_ms"`
+	Healthy    int     `json:"healthy"`
+	Unhealthy  int     `json:"unhealthy"`
+	// Misconfigured also counts the enabled analyzers without health check, which are not listed in Plugins.
+	Misconfigured int            `json:"misconfigured"`
+	Plugins       []PluginHealth `json:"plugins"`
+}
+
+// healthCheckTarget represents a plugin to check during a sweep.
+type healthCheckTarget struct {
+	health PluginHealth
+	check  func(ctx context.Context, name string) (bool, error)
+}
+
+// newHealthCheckTarget flags the plugin when its verification failed.
+func newHealthCheckTarget(name string, pluginType string, verification VerificationType, check func(ctx context.Context, name string) (bool, error)) healthCheckTarget {
+	health := PluginHealth{
+		Name:              name,
+		Type:              pluginType,
+		VerificationError: verification.ErrorMessage,
+	}
+	if len(verification.MissingSecrets) > 0 {
+		health.MissingSecrets = verification.MissingSecrets
+	}
+	health.Misconfigured = !verification.Configured || len(verification.MissingSecrets) > 0
+	return healthCheckTarget{health: health, check: check}
+}
+
+// HealthReport fetches every analyzer and connector configuration and health checks the enabled ones concurrently.
+// Only the plugins supporting health checks are included: docker based analyzers and connectors.
+// The other enabled analyzers are only counted in Misconfigured when their verification failed.
+// The report is sorted by type then name and can be marshalled as is for monitoring.
+//
+//	Endpoint: GET /api/analyzer/{NameOfAnalyzer}/healthcheck
+//	Endpoint: GET /api/connector/{NameOfConnector}/healthcheck
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/analyzer/operation/analyzer_healthcheck_retrieve
+func (client *Client) HealthReport(ctx context.Context, options *HealthReportOptions) (*HealthReport, error) {
+	if options == nil {
+		options = &HealthReportOptions{}
+	}
+	startedAt := time.Now()
+	analyzerConfigs, err := client.AnalyzerService.fetchConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	connectorConfigs, err := client.ConnectorService.fetchConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := []healthCheckTarget{}
+	uncheckedMisconfigured := 0
+	for name, analyzerConfig := range analyzerConfigs {
+		if analyzerConfig.Disabled {
+			continue
+		}
+		target := newHealthCheckTarget(name, PluginTypeAnalyzer, analyzerConfig.Verification, client.AnalyzerService.HealthCheck)
+		if !analyzerConfig.DockerBased {
+			if target.health.Misconfigured {
+				uncheckedMisconfigured++
+			}
+			continue
+		}
+		targets = append(targets, target)
+	}
+	for name, connectorConfig := range connectorConfigs {
+		if connectorConfig.Disabled {
+			continue
+		}
+		targets = append(targets, newHealthCheckTarget(name, PluginTypeConnector, connectorConfig.Verification, client.ConnectorService.HealthCheck))
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].health.Type != targets[j].health.Type {
+			return targets[i].health.Type < targets[j].health.Type
+		}
+		return targets[i].health.Name < targets[j].health.Name
+	})
+
+	// * every goroutine writes its own index so only the counters need the mutex
+	report := &HealthReport{
+		CheckedAt:     startedAt,
+		Misconfigured: uncheckedMisconfigured,
+		Plugins:       make([]PluginHealth, len(targets)),
+	}
+	var mutex sync.Mutex
+	started := runConcurrently(ctx, len(targets), options.Concurrency, func(ctx context.Context, index int) {
+		target := targets[index]
+		health := target.health
+		checkStartedAt := time.Now()
+		healthy, err := target.check(ctx, health.Name)
+		health.LatencyMs = float64(time.Since(checkStartedAt)) / float64(time.Millisecond)
+		health.Healthy = healthy && err == nil
+		if err != nil {
+			health.Error = err.Error()
+		}
+		report.Plugins[index] = health
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		if health.Healthy {
+			report.Healthy++
+		} else {
+			report.Unhealthy++
+		}
+		if health.Misconfigured {
+			report.Misconfigured++
+		}
+	})
+	// * the plugins skipped because ctx was done are reported unhealthy with the context error
+	for index := started; index < len(targets); index++ {
+		health := targets[index].health
+		health.Error = ctx.Err().Error()
+		report.Plugins[index] = health
+		report.Unhealthy++
+		if health.Misconfigured {
+			report.Misconfigured++
+		}
+	}
+	report.DurationMs = float64(time.Since(startedAt)) / float64(time.Millisecond)
+	return report, nil
+}
@@ -0,0 +1,211 @@
+package gothreatmatrix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/khulnasoft/go-threatmatrix/constants"
+)
+
+// CrontabSchedule represents when an ingestor pulls its feed, every field follows the crontab syntax.
+type CrontabSchedule struct {
+	Minute      string `json:"minute"`
+	Hour        string `json:"hour"`
+	DayOfWeek   string `json:"day_of_week"`
+	DayOfMonth  string `json:"day_of_month"`
+	MonthOfYear string `json:"month_of_year"`
+}
+
+// String returns the schedule as a crontab expression, e.g "0 */6 * * *".
+func (schedule CrontabSchedule) String() string {
+	return strings.Join([]string{schedule.Minute, schedule.Hour, schedule.DayOfMonth, schedule.MonthOfYear, schedule.DayOfWeek}, " ")
+}
+
+// ParseCrontab turns a 5 field crontab expression, "minute hour day_of_month month_of_year day_of_week", into a CrontabSchedule.
+func ParseCrontab(expression string) (CrontabSchedule, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return CrontabSchedule{}, fmt.Errorf("Invalid crontab expression %q: expected 5 fields, got %d", expression, len(fields))
+	}
+	return CrontabSchedule{
+		Minute:      fields[0],
+		Hour:        fields[1],
+		DayOfMonth:  fields[2],
+		MonthOfYear: fields[3],
+		DayOfWeek:   fields[4],
+	}, nil
+}
+
+// IngestorConfig represents how an ingestor is configured in ThreatMatrix.
+// An ingestor periodically pulls a threat feed and creates a job, as User, running PlaybookToExecute for each entry.
+//
+// ThreatMatrix docs: https://threatmatrix.readthedocs.io/en/latest/Usage.html#ingestors
+type IngestorConfig struct {
+	BaseConfigurationType
+	Schedule          CrontabSchedule `json:"schedule"`
+	PlaybookToExecute string          `json:"playbook_to_execute"`
+	MaximumJobs       int             `json:"maximum_jobs"`
+	User              UserDetails     `json:"user"`
+}
+
+// IngestorService handles communication with ingestor related methods of the ThreatMatrix API.
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/ingestor
+type IngestorService struct {
+	client *Client
+}
+
+// GetConfigs lists down every ingestor configuration in your ThreatMatrix instance.
+//
+//	Endpoint: GET /api/ingestor
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/ingestor/operation/ingestor_list
+func (ingestorService *IngestorService) GetConfigs(ctx context.Context) (*[]IngestorConfig, error) {
+	ingestorConfigurationResponse, err := ingestorService.fetchConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ingestorNames := make([]string, 0)
+	// *getting all the ingestor key names!
+	for ingestorName := range ingestorConfigurationResponse {
+		ingestorNames = append(ingestorNames, ingestorName)
+	}
+	// * sorting them alphabetically
+	sort.Strings(ingestorNames)
+	ingestorConfigurationList := []IngestorConfig{}
+	for _, ingestorName := range ingestorNames {
+		ingestorConfig := ingestorConfigurationResponse[ingestorName]
+		ingestorConfigurationList = append(ingestorConfigurationList, ingestorConfig)
+	}
+	return &ingestorConfigurationList, nil
+}
+
+// fetchConfigs downloads every ingestor configuration indexed by name.
+func (ingestorService *IngestorService) fetchConfigs(ctx context.Context) (map[string]IngestorConfig, error) {
+	requestUrl := ingestorService.client.options.Url + constants.INGESTOR_CONFIG_URL
+	contentType := constants.ContentTypeJSON
+	method := http.MethodGet
+	request, err := ingestorService.client.buildRequest(ctx, method, contentType, nil, requestUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	successResp, err := ingestorService.client.newRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	ingestorConfigurationResponse := map[string]IngestorConfig{}
+	if unmarshalError := json.Unmarshal(successResp.Data, &ingestorConfigurationResponse); unmarshalError != nil {
+		return nil, unmarshalError
+	}
+	return ingestorConfigurationResponse, nil
+}
+
+// Get fetches the configuration of the specified ingestor.
+//
+//	Endpoint: GET /api/ingestor/{NameOfIngestor}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/ingestor/operation/ingestor_retrieve
+func (ingestorService *IngestorService) Get(ctx context.Context, ingestorName string) (*IngestorConfig, error) {
+	ingestorConfig := IngestorConfig{}
+	if err := ingestorService.client.getPluginConfig(ctx, constants.SPECIFIC_INGESTOR_CONFIG_URL, ingestorName, &ingestorConfig); err != nil {
+		return nil, err
+	}
+	return &ingestorConfig, nil
+}
+
+// Enable lets you enable an ingestor.
+//
+//	Endpoint: PATCH /api/ingestor/{NameOfIngestor}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/ingestor/operation/ingestor_partial_update
+func (ingestorService *IngestorService) Enable(ctx context.Context, ingestorName string) (*IngestorConfig, error) {
+	ingestorConfig := IngestorConfig{}
+	if err := ingestorService.client.setPluginDisabled(ctx, constants.SPECIFIC_INGESTOR_CONFIG_URL, ingestorName, false, &ingestorConfig); err != nil {
+		return nil, err
+	}
+	return &ingestorConfig, nil
+}
+
+// Disable lets you disable an ingestor.
+//
+//	Endpoint: PATCH /api/ingestor/{NameOfIngestor}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/ingestor/operation/ingestor_partial_update
+func (ingestorService *IngestorService) Disable(ctx context.Context, ingestorName string) (*IngestorConfig, error) {
+	ingestorConfig := IngestorConfig{}
+	if err := ingestorService.client.setPluginDisabled(ctx, constants.SPECIFIC_INGESTOR_CONFIG_URL, ingestorName, true, &ingestorConfig); err != nil {
+		return nil, err
+	}
+	return &ingestorConfig, nil
+}
+
+// UpdateSchedule changes when the specified ingestor pulls its feed.
+//
+//	Endpoint: PATCH /api/ingestor/{NameOfIngestor}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/ingestor/operation/ingestor_partial_update
+func (ingestorService *IngestorService) UpdateSchedule(ctx context.Context, ingestorName string, schedule CrontabSchedule) (*IngestorConfig, error) {
+	if _, err := ParseCrontab(schedule.String()); err != nil {
+		return nil, err
+	}
+	ingestorConfig := IngestorConfig{}
+	fields := map[string]interface{}{"schedule": schedule}
+	if err := ingestorService.client.patchPluginConfig(ctx, constants.SPECIFIC_INGESTOR_CONFIG_URL, ingestorName, fields, &ingestorConfig); err != nil {
+		return nil, err
+	}
+	return &ingestorConfig, nil
+}
+
+// SetParams sets organization-level values of the ingestor's params, the ones your organization already overrides are updated.
+// Every param must be declared by the ingestor, the updated configuration is then fetched again.
+//
+//	Endpoint: POST /api/plugin-config
+//	Endpoint: PATCH /api/plugin-config/{id}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/plugin-config
+func (ingestorService *IngestorService) SetParams(ctx context.Context, ingestorName string, params map[string]interface{}) (*IngestorConfig, error) {
+	ingestorConfig, err := ingestorService.Get(ctx, ingestorName)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPluginAttributes(ingestorName, "params", ingestorConfig.paramNames(), sortedKeys(params)); err != nil {
+		return nil, err
+	}
+	if err := ingestorService.client.OrganizationService.setValues(ctx, PluginTypeIngestor, ingestorName, params, nil); err != nil {
+		return nil, err
+	}
+	return ingestorService.Get(ctx, ingestorName)
+}
+
+// ListJobs fetches every job the specified ingestor created, that is every job submitted by the ingestor's user.
+//
+//	Endpoint: GET /api/jobs?user={username}&page={page}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/jobs/operation/jobs_list
+func (ingestorService *IngestorService) ListJobs(ctx context.Context, ingestorName string) ([]JobList, error) {
+	ingestorConfig, err := ingestorService.Get(ctx, ingestorName)
+	if err != nil {
+		return nil, err
+	}
+	if ingestorConfig.User.Username == "" {
+		return nil, fmt.Errorf("Ingestor %s has no user", ingestorName)
+	}
+	jobs, err := ingestorService.client.JobService.Search(ctx, &JobQuery{Username: ingestorConfig.User.Username})
+	if err != nil {
+		return nil, err
+	}
+	// * ThreatMatrix matches the username as a substring
+	ingestorJobs := []JobList{}
+	for _, job := range jobs {
+		if job.User.Username == ingestorConfig.User.Username {
+			ingestorJobs = append(ingestorJobs, job)
+		}
+	}
+	return ingestorJobs, nil
+}
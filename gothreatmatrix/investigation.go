@@ -0,0 +1,334 @@
+package gothreatmatrix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/khulnasoft/go-threatmatrix/constants"
+)
+
+// InvestigationStatus represents the state of an investigation.
+type InvestigationStatus string
+
+// Values of the InvestigationStatus enum.
+const (
+	InvestigationCreated   InvestigationStatus = "created"
+	InvestigationRunning   InvestigationStatus = "running"
+	InvestigationConcluded InvestigationStatus = "concluded"
+)
+
+// IsValid reports whether the status is one ThreatMatrix knows.
+func (status InvestigationStatus) IsValid() bool {
+	switch status {
+	case InvestigationCreated, InvestigationRunning, InvestigationConcluded:
+		return true
+	}
+	return false
+}
+
+// InvestigationOwner represents the user owning an investigation.
+type InvestigationOwner struct {
+	Username string `json:"username"`
+}
+
+// UnmarshalJSON lets you implement the json.Unmarshaler interface, the owner is sent either as a username or as a user object.
+func (owner *InvestigationOwner) UnmarshalJSON(data []byte) error {
+	var username string
+	if err := json.Unmarshal(data, &username); err == nil {
+		owner.Username = username
+		return nil
+	}
+	type investigationOwner InvestigationOwner
+	return json.Unmarshal(data, (*investigationOwner)(owner))
+}
+
+// InvestigationParams represents the fields needed for creating and updating an investigation.
+// Empty fields are left untouched by an update.
+type InvestigationParams struct {
+	Name        string              `json:"name,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Status      InvestigationStatus `json:"status,omitempty"`
+}
+
+// Investigation represents a group of related jobs analysts work on together.
+type Investigation struct {
+	ID          uint64              `json:"id"`
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Owner       InvestigationOwner  `json:"owner"`
+	Tags        []string            `json:"tags"`
+	Tlp         TLP                 `json:"tlp"`
+	Status      InvestigationStatus `json:"status"`
+	Jobs        []uint64            `json:"jobs"`
+	TotalJobs   int                 `json:"total_jobs"`
+	StartTime   time.Time           `json:"start_time"`
+	EndTime     *time.Time          `json:"end_time"`
+}
+
+// InvestigationListResponse represents a page of investigations.
+type InvestigationListResponse struct {
+	Count      int             `json:"count"`
+	TotalPages int             `json:"total_pages"`
+	Results    []Investigation `json:"results"`
+}
+
+// InvestigationTreeJob represents a job of an investigation tree and the jobs it pivoted into.
+type InvestigationTreeJob struct {
+	ID                 uint64                 `json:"pk"`
+	AnalyzedObjectName string                 `json:"analyzed_object_name"`
+	Playbook           string                 `json:"playbook"`
+	Status             string                 `json:"status"`
+	IsSample           bool                   `json:"is_sample"`
+	Children           []InvestigationTreeJob `json:"children"`
+}
+
+// InvestigationTree represents the jobs of an investigation with their pivoted children.
+type InvestigationTree struct {
+	Name   string                 `json:"name"`
+	Owner  InvestigationOwner     `json:"owner"`
+	Status InvestigationStatus    `json:"status"`
+	Jobs   []InvestigationTreeJob `json:"jobs"`
+}
+
+// investigationJobParams represents the body used to add a job to or remove a job from an investigation.
+type investigationJobParams struct {
+	Job uint64 `json:"job"`
+}
+
+// InvestigationService handles communication with investigation related methods of ThreatMatrix API.
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/investigation
+type InvestigationService struct {
+	client *Client
+}
+
+// checkInvestigationID is used to check if an investigation ID is valid (id should be greater than zero).
+func checkInvestigationID(id uint64) error {
+	if id > 0 {
+		return nil
+	}
+	return errors.New("Investigation ID cannot be 0")
+}
+
+// checkInvestigationParams rejects an unknown status before it reaches the server.
+func checkInvestigationParams(investigationParams *InvestigationParams) error {
+	if investigationParams.Status != "" && !investigationParams.Status.IsValid() {
+		return fmt.Errorf("Invalid investigation status %q", investigationParams.Status)
+	}
+	return nil
+}
+
+// sendInvestigation sends a body to an investigation endpoint and decodes the answer into investigation.
+func (investigationService *InvestigationService) sendInvestigation(ctx context.Context, method string, requestUrl string, payload interface{}, investigation interface{}) error {
+	contentType := constants.ContentTypeJSON
+	var body io.Reader
+	if payload != nil {
+		payloadJson, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewBuffer(payloadJson)
+	}
+	request, err := investigationService.client.buildRequest(ctx, method, contentType, body, requestUrl)
+	if err != nil {
+		return err
+	}
+	successResp, err := investigationService.client.newRequest(ctx, request)
+	if err != nil {
+		return err
+	}
+	if unmarshalError := json.Unmarshal(successResp.Data, investigation); unmarshalError != nil {
+		return unmarshalError
+	}
+	return nil
+}
+
+// Create lets you open a new investigation by passing InvestigationParams.
+//
+//	Endpoint: POST /api/investigation
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/investigation/operation/investigation_create
+func (investigationService *InvestigationService) Create(ctx context.Context, investigationParams *InvestigationParams) (*Investigation, error) {
+	if investigationParams.Name == "" {
+		return nil, errors.New("Investigation name cannot be empty")
+	}
+	if err := checkInvestigationParams(investigationParams); err != nil {
+		return nil, err
+	}
+	requestUrl := investigationService.client.options.Url + constants.BASE_INVESTIGATION_URL
+	investigation := Investigation{}
+	if err := investigationService.sendInvestigation(ctx, http.MethodPost, requestUrl, investigationParams, &investigation); err != nil {
+		return nil, err
+	}
+	return &investigation, nil
+}
+
+// List fetches a page of the investigations you can see, page starts at 1.
+//
+//	Endpoint: GET /api/investigation?page={page}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/investigation/operation/investigation_list
+func (investigationService *InvestigationService) List(ctx context.Context, page int) (*InvestigationListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	requestUrl := fmt.Sprintf("%s%s?page=%d", investigationService.client.options.Url, constants.BASE_INVESTIGATION_URL, page)
+	investigationList := InvestigationListResponse{}
+	if err := investigationService.sendInvestigation(ctx, http.MethodGet, requestUrl, nil, &investigationList); err != nil {
+		return nil, err
+	}
+	return &investigationList, nil
+}
+
+// ListAll fetches every page of the investigations you can see.
+//
+//	Endpoint: GET /api/investigation?page={page}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/investigation/operation/investigation_list
+func (investigationService *InvestigationService) ListAll(ctx context.Context) ([]Investigation, error) {
+	investigations := []Investigation{}
+	for page := 1; ; page++ {
+		investigationList, err := investigationService.List(ctx, page)
+		if err != nil {
+			return nil, err
+		}
+		investigations = append(investigations, investigationList.Results...)
+		if page >= investigationList.TotalPages || len(investigationList.Results) == 0 {
+			return investigations, nil
+		}
+	}
+}
+
+// Get fetches a specific investigation through its investigation ID.
+//
+//	Endpoint: GET /api/investigation/{id}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/investigation/operation/investigation_retrieve
+func (investigationService *InvestigationService) Get(ctx context.Context, investigationId uint64) (*Investigation, error) {
+	if err := checkInvestigationID(investigationId); err != nil {
+		return nil, err
+	}
+	route := investigationService.client.options.Url + constants.SPECIFIC_INVESTIGATION_URL
+	requestUrl := fmt.Sprintf(route, investigationId)
+	investigation := Investigation{}
+	if err := investigationService.sendInvestigation(ctx, http.MethodGet, requestUrl, nil, &investigation); err != nil {
+		return nil, err
+	}
+	return &investigation, nil
+}
+
+// Update lets you edit an investigation, only the fields set in InvestigationParams are changed.
+//
+//	Endpoint: PATCH /api/investigation/{id}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/investigation/operation/investigation_partial_update
+func (investigationService *InvestigationService) Update(ctx context.Context, investigationId uint64, investigationParams *InvestigationParams) (*Investigation, error) {
+	if err := checkInvestigationID(investigationId); err != nil {
+		return nil, err
+	}
+	if err := checkInvestigationParams(investigationParams); err != nil {
+		return nil, err
+	}
+	route := investigationService.client.options.Url + constants.SPECIFIC_INVESTIGATION_URL
+	requestUrl := fmt.Sprintf(route, investigationId)
+	investigation := Investigation{}
+	if err := investigationService.sendInvestigation(ctx, http.MethodPatch, requestUrl, investigationParams, &investigation); err != nil {
+		return nil, err
+	}
+	return &investigation, nil
+}
+
+// Delete removes the given investigation, its jobs are kept.
+//
+//	Endpoint: DELETE /api/investigation/{id}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/investigation/operation/investigation_destroy
+func (investigationService *InvestigationService) Delete(ctx context.Context, investigationId uint64) (bool, error) {
+	if err := checkInvestigationID(investigationId); err != nil {
+		return false, err
+	}
+	route := investigationService.client.options.Url + constants.SPECIFIC_INVESTIGATION_URL
+	requestUrl := fmt.Sprintf(route, investigationId)
+	contentType := constants.ContentTypeJSON
+	method := http.MethodDelete
+	request, err := investigationService.client.buildRequest(ctx, method, contentType, nil, requestUrl)
+	if err != nil {
+		return false, err
+	}
+	successResp, err := investigationService.client.newRequest(ctx, request)
+	if err != nil {
+		return false, err
+	}
+	if successResp.StatusCode == http.StatusNoContent {
+		return true, nil
+	}
+	return false, nil
+}
+
+// changeJob adds a job to or removes a job from an investigation.
+func (investigationService *InvestigationService) changeJob(ctx context.Context, route string, investigationId uint64, jobId uint64) (bool, error) {
+	if err := checkInvestigationID(investigationId); err != nil {
+		return false, err
+	}
+	if jobId == 0 {
+		return false, errors.New("Job ID cannot be 0")
+	}
+	requestUrl := investigationService.client.options.Url + fmt.Sprintf(route, investigationId)
+	jobJson, err := json.Marshal(investigationJobParams{Job: jobId})
+	if err != nil {
+		return false, err
+	}
+	contentType := constants.ContentTypeJSON
+	method := http.MethodPost
+	request, err := investigationService.client.buildRequest(ctx, method, contentType, bytes.NewBuffer(jobJson), requestUrl)
+	if err != nil {
+		return false, err
+	}
+	if _, err := investigationService.client.newRequest(ctx, request); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// AddJob adds a job to an investigation.
+//
+//	Endpoint: POST /api/investigation/{id}/add_job
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/investigation/operation/investigation_add_job_create
+func (investigationService *InvestigationService) AddJob(ctx context.Context, investigationId uint64, jobId uint64) (bool, error) {
+	return investigationService.changeJob(ctx, constants.ADD_JOB_INVESTIGATION_URL, investigationId, jobId)
+}
+
+// RemoveJob removes a job from an investigation, the job itself is kept.
+//
+//	Endpoint: POST /api/investigation/{id}/remove_job
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/investigation/operation/investigation_remove_job_create
+func (investigationService *InvestigationService) RemoveJob(ctx context.Context, investigationId uint64, jobId uint64) (bool, error) {
+	return investigationService.changeJob(ctx, constants.REMOVE_JOB_INVESTIGATION_URL, investigationId, jobId)
+}
+
+// Tree fetches the jobs of an investigation with, recursively, the jobs they pivoted into.
+//
+//	Endpoint: GET /api/investigation/{id}/tree
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/investigation/operation/investigation_tree_retrieve
+func (investigationService *InvestigationService) Tree(ctx context.Context, investigationId uint64) (*InvestigationTree, error) {
+	if err := checkInvestigationID(investigationId); err != nil {
+		return nil, err
+	}
+	route := investigationService.client.options.Url + constants.INVESTIGATION_TREE_URL
+	requestUrl := fmt.Sprintf(route, investigationId)
+	tree := InvestigationTree{}
+	if err := investigationService.sendInvestigation(ctx, http.MethodGet, requestUrl, nil, &tree); err != nil {
+		return nil, err
+	}
+	return &tree, nil
+}
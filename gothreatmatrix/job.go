@@ -1,10 +1,15 @@
 package gothreatmatrix
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/khulnasoft/go-threatmatrix/constants"
@@ -54,9 +59,11 @@ type BaseJob struct {
 // Job represents a job that is being processed in ThreatMatrix.
 type Job struct {
 	BaseJob
-	AnalyzerReports  []Report               `json:"analyzer_reports"`
-	ConnectorReports []Report               `json:"connector_reports"`
-	Permission       map[string]interface{} `json:"permission"`
+	AnalyzerReports   []Report               `json:"analyzer_reports"`
+	ConnectorReports  []Report               `json:"connector_reports"`
+	VisualizerReports []VisualizerReport     `json:"visualizer_reports"`
+	Permission        map[string]interface{} `json:"permission"`
+	Comments          []Comment              `json:"comments,omitempty"`
 }
 
 // JobList represents a list of jobs in ThreatMatrix.
@@ -137,9 +144,9 @@ func (jobService *JobService) Get(ctx context.Context, jobId uint64) (*Job, erro
 func (jobService *JobService) DownloadSample(ctx context.Context, jobId uint64) ([]byte, error) {
 	route := jobService.client.options.Url + constants.DOWNLOAD_SAMPLE_JOB_URL
 	requestUrl := fmt.Sprintf(route, jobId)
-	contentType := constants.ContentTypeJSON
 	method := http.MethodGet
-	request, err := jobService.client.buildRequest(ctx, method, contentType, nil, requestUrl)
+	// * the sample is binary so no Content-Type is sent
+	request, err := jobService.client.buildRequest(ctx, method, "", nil, requestUrl)
 	if err != nil {
 		return nil, err
 	}
@@ -293,3 +300,289 @@ func (jobService *JobService) RetryConnector(ctx context.Context, jobId uint64,
 	}
 	return false, nil
 }
+
+// RescanResponse represents the response returned by ThreatMatrix when a job is rescanned.
+type RescanResponse struct {
+	ID int `json:"id"`
+}
+
+// Rescan lets you re-run a job on the server with the same parameters it was submitted with.
+// It returns the ID of the newly created job, which counts against the QuotaGuard.
+//
+//	Endpoint: POST /api/jobs/{jobID}/rescan
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/jobs/operation/jobs_rescan_create
+func (jobService *JobService) Rescan(ctx context.Context, jobId uint64) (int, error) {
+	route := jobService.client.options.Url + constants.RESCAN_JOB_URL
+	requestUrl := fmt.Sprintf(route, jobId)
+	contentType := constants.ContentTypeJSON
+	method := http.MethodPost
+	request, err := jobService.client.buildRequest(ctx, method, contentType, nil, requestUrl)
+	if err != nil {
+		return 0, err
+	}
+	successResp, err := jobService.client.newSubmissionRequest(ctx, request, 1)
+	if err != nil {
+		return 0, err
+	}
+	rescanResponse := RescanResponse{}
+	if unmarshalError := json.Unmarshal(successResp.Data, &rescanResponse); unmarshalError != nil {
+		return 0, unmarshalError
+	}
+	return rescanResponse.ID, nil
+}
+
+// BasicAnalysisParamsFromJob rebuilds the BasicAnalysisParams a job was submitted with.
+// Non-zero fields of overrides take precedence over the values found in the job.
+func BasicAnalysisParamsFromJob(job *Job, overrides *BasicAnalysisParams) BasicAnalysisParams {
+	tagsLabels := make([]string, 0, len(job.Tags))
+	for _, tag := range job.Tags {
+		tagsLabels = append(tagsLabels, tag.Label)
+	}
+	params := BasicAnalysisParams{
+		Tlp:                  ParseTLP(job.Tlp),
+		RuntimeConfiguration: map[string]interface{}{},
+		AnalyzersRequested:   job.AnalyzersRequested,
+		ConnectorsRequested:  job.ConnectorsRequested,
+		TagsLabels:           tagsLabels,
+	}
+	if overrides == nil {
+		return params
+	}
+	if overrides.User != 0 {
+		params.User = overrides.User
+	}
+	if overrides.Tlp != 0 {
+		params.Tlp = overrides.Tlp
+	}
+	if overrides.RuntimeConfiguration != nil {
+		params.RuntimeConfiguration = overrides.RuntimeConfiguration
+	}
+	if overrides.AnalyzersRequested != nil {
+		params.AnalyzersRequested = overrides.AnalyzersRequested
+	}
+	if overrides.ConnectorsRequested != nil {
+		params.ConnectorsRequested = overrides.ConnectorsRequested
+	}
+	if overrides.TagsLabels != nil {
+		params.TagsLabels = overrides.TagsLabels
+	}
+	return params
+}
+
+// ObservableAnalysisParamsFromJob rebuilds the ObservableAnalysisParams of an observable job.
+func ObservableAnalysisParamsFromJob(job *Job, overrides *BasicAnalysisParams) (*ObservableAnalysisParams, error) {
+	if job.IsSample {
+		return nil, fmt.Errorf("job %d is a file analysis", job.ID)
+	}
+	return &ObservableAnalysisParams{
+		BasicAnalysisParams:      BasicAnalysisParamsFromJob(job, overrides),
+		ObservableName:           job.ObservableName,
+		ObservableClassification: job.ObservableClassification,
+	}, nil
+}
+
+// Resubmit is the client-side fallback of Rescan: it fetches the job, rebuilds its analysis params and submits them again.
+// File jobs get their sample through DownloadSample first.
+// Non-zero fields of overrides take precedence over the values found in the job.
+func (jobService *JobService) Resubmit(ctx context.Context, jobId uint64, overrides *BasicAnalysisParams) (*AnalysisResponse, error) {
+	job, err := jobService.Get(ctx, jobId)
+	if err != nil {
+		return nil, err
+	}
+	if !job.IsSample {
+		observableParams, err := ObservableAnalysisParamsFromJob(job, overrides)
+		if err != nil {
+			return nil, err
+		}
+		return jobService.client.CreateObservableAnalysis(ctx, observableParams)
+	}
+
+	sample, err := jobService.DownloadSample(ctx, jobId)
+	if err != nil {
+		return nil, err
+	}
+	// * The multipart form uses the file's base name, so the sample keeps its original name inside a temporary directory
+	sampleDir, err := os.MkdirTemp("", "gothreatmatrix-sample-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(sampleDir)
+	fileName := filepath.Base(job.FileName)
+	if fileName == "." || fileName == string(filepath.Separator) {
+		fileName = job.Md5
+	}
+	samplePath := filepath.Join(sampleDir, fileName)
+	if err := os.WriteFile(samplePath, sample, 0600); err != nil {
+		return nil, err
+	}
+	file, err := os.Open(samplePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	fileParams := &FileAnalysisParams{
+		BasicAnalysisParams: BasicAnalysisParamsFromJob(job, overrides),
+		File:                file,
+	}
+	return jobService.client.CreateFileAnalysis(ctx, fileParams)
+}
+
+// jobTagsParams represents the fields needed for replacing the tags of a job.
+type jobTagsParams struct {
+	Tags []uint64 `json:"tags"`
+}
+
+// SetTags replaces the tags attached to a job with the given tag IDs.
+//
+//	Endpoint: PATCH /api/jobs/{jobID}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/jobs/operation/jobs_partial_update
+func (jobService *JobService) SetTags(ctx context.Context, jobId uint64, tagIds []uint64) (*Job, error) {
+	for _, tagId := range tagIds {
+		if err := checkTagID(tagId); err != nil {
+			return nil, err
+		}
+	}
+	route := jobService.client.options.Url + constants.SPECIFIC_JOB_URL
+	requestUrl := fmt.Sprintf(route, jobId)
+	if tagIds == nil {
+		tagIds = []uint64{}
+	}
+	tagsJson, err := json.Marshal(&jobTagsParams{Tags: tagIds})
+	if err != nil {
+		return nil, err
+	}
+	contentType := constants.ContentTypeJSON
+	method := http.MethodPatch
+	body := bytes.NewBuffer(tagsJson)
+	request, err := jobService.client.buildRequest(ctx, method, contentType, body, requestUrl)
+	if err != nil {
+		return nil, err
+	}
+	successResp, err := jobService.client.newRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	jobResponse := Job{}
+	if unmarshalError := json.Unmarshal(successResp.Data, &jobResponse); unmarshalError != nil {
+		return nil, unmarshalError
+	}
+	return &jobResponse, nil
+}
+
+// updateTags fetches the job's tags, lets change edit the set of tag IDs and saves it back.
+func (jobService *JobService) updateTags(ctx context.Context, jobId uint64, change func(tagIds map[uint64]bool)) (*Job, error) {
+	job, err := jobService.Get(ctx, jobId)
+	if err != nil {
+		return nil, err
+	}
+	tagIds := map[uint64]bool{}
+	for _, tag := range job.Tags {
+		tagIds[tag.ID] = true
+	}
+	change(tagIds)
+	// * keeping the job's order and appending the new tags after it
+	updatedTagIds := []uint64{}
+	for _, tag := range job.Tags {
+		if tagIds[tag.ID] {
+			updatedTagIds = append(updatedTagIds, tag.ID)
+			delete(tagIds, tag.ID)
+		}
+	}
+	newTagIds := []uint64{}
+	for tagId := range tagIds {
+		newTagIds = append(newTagIds, tagId)
+	}
+	sort.Slice(newTagIds, func(i, j int) bool { return newTagIds[i] < newTagIds[j] })
+	return jobService.SetTags(ctx, jobId, append(updatedTagIds, newTagIds...))
+}
+
+// AddTags attaches the given tags to a job, keeping the tags it already has.
+func (jobService *JobService) AddTags(ctx context.Context, jobId uint64, tagIds []uint64) (*Job, error) {
+	return jobService.updateTags(ctx, jobId, func(jobTagIds map[uint64]bool) {
+		for _, tagId := range tagIds {
+			jobTagIds[tagId] = true
+		}
+	})
+}
+
+// RemoveTags detaches the given tags from a job.
+func (jobService *JobService) RemoveTags(ctx context.Context, jobId uint64, tagIds []uint64) (*Job, error) {
+	return jobService.updateTags(ctx, jobId, func(jobTagIds map[uint64]bool) {
+		for _, tagId := range tagIds {
+			delete(jobTagIds, tagId)
+		}
+	})
+}
+
+// AddTagsByLabel attaches the tags with the given labels to a job, matching labels case-insensitively.
+// Labels without a tag are created when autoCreate is set, otherwise they make it fail.
+func (jobService *JobService) AddTagsByLabel(ctx context.Context, jobId uint64, labels []string, autoCreate bool) (*Job, error) {
+	tags, err := jobService.client.TagService.resolveTagLabels(ctx, labels, autoCreate)
+	if err != nil {
+		return nil, err
+	}
+	return jobService.AddTags(ctx, jobId, tagIDs(tags))
+}
+
+// RemoveTagsByLabel detaches the tags with the given labels from a job, matching labels case-insensitively.
+func (jobService *JobService) RemoveTagsByLabel(ctx context.Context, jobId uint64, labels []string) (*Job, error) {
+	removedLabels := map[string]bool{}
+	for _, label := range labels {
+		removedLabels[strings.ToLower(label)] = true
+	}
+	job, err := jobService.Get(ctx, jobId)
+	if err != nil {
+		return nil, err
+	}
+	tagIds := []uint64{}
+	for _, tag := range job.Tags {
+		if !removedLabels[strings.ToLower(tag.Label)] {
+			tagIds = append(tagIds, tag.ID)
+		}
+	}
+	return jobService.SetTags(ctx, jobId, tagIds)
+}
+
+// tagIDs returns the IDs of the given tags.
+func tagIDs(tags []Tag) []uint64 {
+	ids := make([]uint64, 0, len(tags))
+	for _, tag := range tags {
+		ids = append(ids, tag.ID)
+	}
+	return ids
+}
+
+// HasTag makes a JobFilter selecting the jobs carrying a tag with the given label, matched case-insensitively.
+func HasTag(label string) JobFilter {
+	return func(job *JobList) bool {
+		for _, tag := range job.Tags {
+			if strings.EqualFold(tag.Label, label) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ListByTag fetches every job carrying a tag with the given label, matched case-insensitively.
+//
+//	Endpoint: GET /api/jobs?tags__label={label}&page={page}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/jobs/operation/jobs_list
+func (jobService *JobService) ListByTag(ctx context.Context, label string) ([]JobList, error) {
+	jobs, err := jobService.Search(ctx, &JobQuery{TagLabel: label})
+	if err != nil {
+		return nil, err
+	}
+	// * HasTag keeps only the exact label matches whatever the server matched
+	filter := HasTag(label)
+	taggedJobs := []JobList{}
+	for index := range jobs {
+		if filter(&jobs[index]) {
+			taggedJobs = append(taggedJobs, jobs[index])
+		}
+	}
+	return taggedJobs, nil
+}
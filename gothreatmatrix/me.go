@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/khulnasoft/go-threatmatrix/constants"
@@ -42,8 +45,40 @@ type Organization struct {
 	MembersCount int        `json:"members_count"`
 	Owner        Owner      `json:"owner"`
 	IsUserOwner  bool       `json:"is_user_owner,omitempty"`
+	IsUserAdmin  bool       `json:"is_user_admin,omitempty"`
 	CreatedAt    *time.Time `json:"created_at,omitempty"`
 	Name         string     `json:"name"`
+	Members      []Member   `json:"members,omitempty"`
+}
+
+// MemberRole represents the role of a member inside an organization.
+type MemberRole string
+
+// Values of the MemberRole enum.
+const (
+	MemberRoleOwner  MemberRole = "owner"
+	MemberRoleAdmin  MemberRole = "admin"
+	MemberRoleMember MemberRole = "member"
+)
+
+// Member represents a user belonging to an organization.
+type Member struct {
+	Username string    `json:"username"`
+	FullName string    `json:"full_name"`
+	Joined   time.Time `json:"joined"`
+	IsAdmin  bool      `json:"is_admin"`
+	IsOwner  bool      `json:"is_owner"`
+}
+
+// Role returns the role of the member, the owner is also an admin but is reported as owner.
+func (member *Member) Role() MemberRole {
+	if member.IsOwner {
+		return MemberRoleOwner
+	}
+	if member.IsAdmin {
+		return MemberRoleAdmin
+	}
+	return MemberRoleMember
 }
 
 type OrganizationParams struct {
@@ -54,6 +89,13 @@ type MemberParams struct {
 	Username string `json:"username"`
 }
 
+// These represent the statuses of an invitation.
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+)
+
 type Invite struct {
 	Id        int       `json:"id"`
 	CreatedAt time.Time `json:"created_at"`
@@ -65,6 +107,14 @@ type Invitation struct {
 	Organization Organization `json:"organization"`
 }
 
+// OrganizationInvitation represents an invitation your organization sent.
+type OrganizationInvitation struct {
+	Invite
+	User Details `json:"user"`
+}
+
+// InvitationParams represents the filters used to list the invitations you received.
+// Status defaults to pending and an empty organization name matches every organization.
 type InvitationParams struct {
 	Organization OrganizationParams `json:"organization"`
 	Status       string             `json:"status"`
@@ -213,3 +263,179 @@ func (userService *UserService) RemoveMemberFromOrganization(ctx context.Context
 	}
 	return false, nil
 }
+
+// Members returns the members of your organization, the owner included.
+// The owner comes first when the API does not list it among the members.
+//
+//	Endpoint: GET /api/me/organization
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/me/operation/me_organization_list
+func (userService *UserService) Members(ctx context.Context) ([]Member, error) {
+	org, err := userService.Organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]Member, 0, len(org.Members)+1)
+	ownerListed := false
+	for _, member := range org.Members {
+		// * the owner is not always flagged by the API
+		if member.Username == org.Owner.Username {
+			member.IsOwner = true
+			member.IsAdmin = true
+			ownerListed = true
+		}
+		members = append(members, member)
+	}
+	if !ownerListed && org.Owner.Username != "" {
+		owner := Member{
+			Username: org.Owner.Username,
+			FullName: org.Owner.FullName,
+			Joined:   org.Owner.Joined,
+			IsAdmin:  true,
+			IsOwner:  true,
+		}
+		members = append([]Member{owner}, members...)
+	}
+	return members, nil
+}
+
+// sendMembership sends an organization membership action, payload can be nil.
+// It returns true when ThreatMatrix accepted the action.
+func (userService *UserService) sendMembership(ctx context.Context, requestUrl string, payload interface{}) (bool, error) {
+	var body io.Reader
+	if payload != nil {
+		payloadJson, err := json.Marshal(payload)
+		if err != nil {
+			return false, err
+		}
+		body = bytes.NewBuffer(payloadJson)
+	}
+	contentType := constants.ContentTypeJSON
+	method := http.MethodPost
+	request, err := userService.client.buildRequest(ctx, method, contentType, body, requestUrl)
+	if err != nil {
+		return false, err
+	}
+	if _, err := userService.client.newRequest(ctx, request); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// LeaveOrganization lets you leave your organization.
+// The owner cannot leave the organization.
+//
+//	Endpoint: POST /api/me/organization/leave
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/me/operation/me_organization_leave_create
+func (userService *UserService) LeaveOrganization(ctx context.Context) (bool, error) {
+	requestUrl := userService.client.options.Url + constants.LEAVE_ORGANIZATION_URL
+	return userService.sendMembership(ctx, requestUrl, nil)
+}
+
+// PromoteAdmin lets you make a member of your organization an admin.
+// This is only accessible to the organization's owner.
+//
+//	Endpoint: POST /api/me/organization/promote_admin
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/me/operation/me_organization_promote_admin_create
+func (userService *UserService) PromoteAdmin(ctx context.Context, memberParams *MemberParams) (bool, error) {
+	requestUrl := userService.client.options.Url + constants.PROMOTE_ADMIN_URL
+	return userService.sendMembership(ctx, requestUrl, memberParams)
+}
+
+// RemoveAdmin lets you take the admin role back from a member of your organization.
+// This is only accessible to the organization's owner.
+//
+//	Endpoint: POST /api/me/organization/remove_admin
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/me/operation/me_organization_remove_admin_create
+func (userService *UserService) RemoveAdmin(ctx context.Context, memberParams *MemberParams) (bool, error) {
+	requestUrl := userService.client.options.Url + constants.REMOVE_ADMIN_URL
+	return userService.sendMembership(ctx, requestUrl, memberParams)
+}
+
+// OrganizationInvitations returns the invitations your organization sent that are still pending.
+// This is only accessible to the organization's owner and admins.
+//
+//	Endpoint: GET /api/me/organization/invitations
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/me/operation/me_organization_invitations_list
+func (userService *UserService) OrganizationInvitations(ctx context.Context) ([]OrganizationInvitation, error) {
+	requestUrl := fmt.Sprintf("%s%s?status=%s", userService.client.options.Url, constants.ORGANIZATION_INVITATIONS_URL, InvitationPending)
+	contentType := constants.ContentTypeJSON
+	method := http.MethodGet
+	request, err := userService.client.buildRequest(ctx, method, contentType, nil, requestUrl)
+	if err != nil {
+		return nil, err
+	}
+	invitations := []OrganizationInvitation{}
+	successResp, err := userService.client.newRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if unmarshalError := json.Unmarshal(successResp.Data, &invitations); unmarshalError != nil {
+		return nil, unmarshalError
+	}
+	return invitations, nil
+}
+
+// Invitations returns the invitations you received, filtered by InvitationParams.
+//
+//	Endpoint: GET /api/me/invitations?status={status}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/me/operation/me_invitations_list
+func (userService *UserService) Invitations(ctx context.Context, invitationParams *InvitationParams) ([]Invitation, error) {
+	if invitationParams == nil {
+		invitationParams = &InvitationParams{}
+	}
+	status := invitationParams.Status
+	if status == "" {
+		status = InvitationPending
+	}
+	requestUrl := fmt.Sprintf("%s%s?status=%s", userService.client.options.Url, constants.INVITATIONS_URL, url.QueryEscape(status))
+	contentType := constants.ContentTypeJSON
+	method := http.MethodGet
+	request, err := userService.client.buildRequest(ctx, method, contentType, nil, requestUrl)
+	if err != nil {
+		return nil, err
+	}
+	received := []Invitation{}
+	successResp, err := userService.client.newRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if unmarshalError := json.Unmarshal(successResp.Data, &received); unmarshalError != nil {
+		return nil, unmarshalError
+	}
+	invitations := []Invitation{}
+	for _, invitation := range received {
+		if invitationParams.Organization.Name != "" && invitation.Organization.Name != invitationParams.Organization.Name {
+			continue
+		}
+		invitations = append(invitations, invitation)
+	}
+	return invitations, nil
+}
+
+// AcceptInvitation lets you join the organization that invited you.
+//
+//	Endpoint: POST /api/me/invitations/{id}/accept
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/me/operation/me_invitations_accept_create
+func (userService *UserService) AcceptInvitation(ctx context.Context, invitationId int) (bool, error) {
+	route := userService.client.options.Url + constants.ACCEPT_INVITATION_URL
+	requestUrl := fmt.Sprintf(route, invitationId)
+	return userService.sendMembership(ctx, requestUrl, nil)
+}
+
+// DeclineInvitation lets you refuse to join the organization that invited you.
+//
+//	Endpoint: POST /api/me/invitations/{id}/decline
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/me/operation/me_invitations_decline_create
+func (userService *UserService) DeclineInvitation(ctx context.Context, invitationId int) (bool, error) {
+	route := userService.client.options.Url + constants.DECLINE_INVITATION_URL
+	requestUrl := fmt.Sprintf(route, invitationId)
+	return userService.sendMembership(ctx, requestUrl, nil)
+}
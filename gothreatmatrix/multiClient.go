@@ -0,0 +1,382 @@
+package gothreatmatrix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultInstanceFailureThreshold is how many consecutive failures mark an instance down when MultiClientOptions.FailureThreshold is not set.
+const DefaultInstanceFailureThreshold = 3
+
+// DefaultInstanceCooldown is how long a down instance is skipped when MultiClientOptions.Cooldown is not set.
+const DefaultInstanceCooldown = time.Minute
+
+var md5Regex = regexp.MustCompile(`^(?i)[0-9a-f]{32}$`)
+
+// Instance represents a named ThreatMatrix instance, e.g one per region or business unit.
+type Instance struct {
+	Name   string
+	Client *Client
+}
+
+// MultiClientOptions represents the optional fields of a MultiClient.
+type MultiClientOptions struct {
+	// FailureThreshold is how many consecutive failures mark an instance down, it defaults to 3.
+	FailureThreshold int
+	// Cooldown is how long a down instance is tried last, after the others, it defaults to 1 minute.
+	Cooldown time.Duration
+	// Concurrency is the maximum number of instances queried at once by the fan-out reads, it defaults to 5.
+	Concurrency int
+}
+
+// InstanceHealth represents the health of an instance as seen by a MultiClient.
+type InstanceHealth struct {
+	Name                string    `json:"name"`
+	Healthy             bool      `json:"healthy"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastError           string    `json:"last_error,omitempty"`
+	LastCheckedAt       time.Time `json:"last_checked_at"`
+	// DownUntil is when a down instance is tried first again, it is zero for healthy instances.
+	DownUntil time.Time `json:"down_until"`
+}
+
+// InstanceAnalysisResponse represents an analysis submitted by a MultiClient with the instance that accepted it.
+type InstanceAnalysisResponse struct {
+	Instance string `json:"instance"`
+	AnalysisResponse
+}
+
+// InstanceJob represents a job found by a MultiClient with the instance it belongs to.
+type InstanceJob struct {
+	Instance string `json:"instance"`
+	JobList
+}
+
+// FanOutError represents the instances that failed during a fan-out read, the results of the others are still returned.
+type FanOutError struct {
+	Errors map[string]error
+}
+
+// Error lets you implement the error interface.
+func (fanOutError *FanOutError) Error() string {
+	names := make([]string, 0, len(fanOutError.Errors))
+	for name := range fanOutError.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	messages := make([]string, 0, len(names))
+	for _, name := range names {
+		messages = append(messages, fmt.Sprintf("%s: %s", name, fanOutError.Errors[name]))
+	}
+	return fmt.Sprintf("%d instances failed: %s", len(names), strings.Join(messages, "; "))
+}
+
+// MultiClient wraps the Clients of several ThreatMatrix instances.
+// Submissions fail over to the next instance when one is down, reads fan out to every instance.
+// The health of each instance is tracked from the outcome of every call.
+type MultiClient struct {
+	instances []Instance
+	options   MultiClientOptions
+	mutex     sync.Mutex
+	health    map[string]*InstanceHealth
+}
+
+// NewMultiClient lets you create a MultiClient over the given instances, submissions try them in this order.
+func NewMultiClient(instances []Instance, options *MultiClientOptions) (*MultiClient, error) {
+	if len(instances) == 0 {
+		return nil, errors.New("At least one instance is required")
+	}
+	multiOptions := MultiClientOptions{}
+	if options != nil {
+		multiOptions = *options
+	}
+	if multiOptions.FailureThreshold <= 0 {
+		multiOptions.FailureThreshold = DefaultInstanceFailureThreshold
+	}
+	if multiOptions.Cooldown <= 0 {
+		multiOptions.Cooldown = DefaultInstanceCooldown
+	}
+	health := map[string]*InstanceHealth{}
+	for _, instance := range instances {
+		if instance.Name == "" || instance.Client == nil {
+			return nil, errors.New("Every instance needs a name and a client")
+		}
+		if _, ok := health[instance.Name]; ok {
+			return nil, fmt.Errorf("Instance %s is defined twice", instance.Name)
+		}
+		health[instance.Name] = &InstanceHealth{Name: instance.Name, Healthy: true}
+	}
+	return &MultiClient{
+		instances: append([]Instance{}, instances...),
+		options:   multiOptions,
+		health:    health,
+	}, nil
+}
+
+// Client returns the Client of the named instance.
+func (multiClient *MultiClient) Client(name string) (*Client, bool) {
+	for _, instance := range multiClient.instances {
+		if instance.Name == name {
+			return instance.Client, true
+		}
+	}
+	return nil, false
+}
+
+// Health returns the health of every instance, in the order they were given.
+func (multiClient *MultiClient) Health() []InstanceHealth {
+	multiClient.mutex.Lock()
+	defer multiClient.mutex.Unlock()
+	health := make([]InstanceHealth, 0, len(multiClient.instances))
+	for _, instance := range multiClient.instances {
+		health = append(health, *multiClient.health[instance.Name])
+	}
+	return health
+}
+
+// isInstanceFailure reports whether an error means the instance is unavailable rather than the request being wrong:
+// a transport error or a 5xx or 429 answer.
+func isInstanceFailure(err error) bool {
+	threatMatrixError := &Error{}
+	if errors.As(err, &threatMatrixError) {
+		return threatMatrixError.StatusCode >= http.StatusInternalServerError || threatMatrixError.StatusCode == http.StatusTooManyRequests
+	}
+	// * transport errors, e.g connection refused or a timeout of the instance
+	urlError := &url.Error{}
+	return errors.As(err, &urlError)
+}
+
+// isNotDelivered reports whether a submission provably never reached the instance, so trying another one can't create the job twice:
+// the connection could not be made, the instance answered 503 or 429, or the QuotaGuard refused it.
+// Timeouts and other 5xx answers may come after the job was created.
+func isNotDelivered(err error) bool {
+	if errors.Is(err, ErrQuotaExceeded) {
+		return true
+	}
+	threatMatrixError := &Error{}
+	if errors.As(err, &threatMatrixError) {
+		return threatMatrixError.StatusCode == http.StatusServiceUnavailable || threatMatrixError.StatusCode == http.StatusTooManyRequests
+	}
+	dnsError := &net.DNSError{}
+	if errors.As(err, &dnsError) {
+		return true
+	}
+	opError := &net.OpError{}
+	return errors.As(err, &opError) && opError.Op == "dial"
+}
+
+// record updates the health of an instance after a call.
+func (multiClient *MultiClient) record(name string, err error) {
+	multiClient.mutex.Lock()
+	defer multiClient.mutex.Unlock()
+	health := multiClient.health[name]
+	health.LastCheckedAt = time.Now()
+	if err == nil || !isInstanceFailure(err) {
+		health.Healthy = true
+		health.ConsecutiveFailures = 0
+		health.LastError = ""
+		health.DownUntil = time.Time{}
+		return
+	}
+	health.ConsecutiveFailures++
+	health.LastError = err.Error()
+	if health.ConsecutiveFailures >= multiClient.options.FailureThreshold {
+		health.Healthy = false
+		health.DownUntil = health.LastCheckedAt.Add(multiClient.options.Cooldown)
+	}
+}
+
+// failoverOrder returns the instances to try for a submission: the available ones first, then the down ones.
+func (multiClient *MultiClient) failoverOrder() []Instance {
+	multiClient.mutex.Lock()
+	defer multiClient.mutex.Unlock()
+	now := time.Now()
+	available := []Instance{}
+	down := []Instance{}
+	for _, instance := range multiClient.instances {
+		health := multiClient.health[instance.Name]
+		if !health.Healthy && now.Before(health.DownUntil) {
+			down = append(down, instance)
+			continue
+		}
+		available = append(available, instance)
+	}
+	return append(available, down...)
+}
+
+// Submit calls submit on one instance after the other until one succeeds and returns the name of that instance.
+// It only moves to the next instance when the submission never reached the current one: the connection failed,
+// the instance answered 503 or 429, or ErrQuotaExceeded. Other errors, e.g invalid params or a timeout
+// after which the job may exist, are returned right away.
+func (multiClient *MultiClient) Submit(ctx context.Context, submit func(ctx context.Context, client *Client) error) (string, error) {
+	var lastError error
+	for _, instance := range multiClient.failoverOrder() {
+		err := submit(ctx, instance.Client)
+		if err != nil && ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		multiClient.record(instance.Name, err)
+		if err == nil {
+			return instance.Name, nil
+		}
+		if isNotDelivered(err) {
+			multiClient.instanceLogger(instance).WithError(err).Warn("Instance unavailable, failing over to the next one")
+			lastError = fmt.Errorf("%s: %w", instance.Name, err)
+			continue
+		}
+		return instance.Name, err
+	}
+	return "", fmt.Errorf("Every instance failed, last error: %w", lastError)
+}
+
+// instanceLogger returns the logger of an instance with its name attached.
+func (multiClient *MultiClient) instanceLogger(instance Instance) *logrus.Entry {
+	return instance.Client.Logger.Logger.WithField("instance", instance.Name)
+}
+
+// CreateObservableAnalysis analyzes an observable on the first available instance.
+//
+//	Endpoint: POST /api/analyze_observable
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/analyze_observable
+func (multiClient *MultiClient) CreateObservableAnalysis(ctx context.Context, params *ObservableAnalysisParams) (*InstanceAnalysisResponse, error) {
+	var analysisResponse *AnalysisResponse
+	name, err := multiClient.Submit(ctx, func(ctx context.Context, client *Client) error {
+		var err error
+		analysisResponse, err = client.CreateObservableAnalysis(ctx, params)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &InstanceAnalysisResponse{Instance: name, AnalysisResponse: *analysisResponse}, nil
+}
+
+// CreateFileAnalysis analyzes a file on the first available instance, the file is read again from its start for every attempt.
+//
+//	Endpoint: POST /api/analyze_file
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/analyze_file
+func (multiClient *MultiClient) CreateFileAnalysis(ctx context.Context, fileAnalysisParams *FileAnalysisParams) (*InstanceAnalysisResponse, error) {
+	if fileAnalysisParams.File == nil {
+		return nil, errors.New("File cannot be nil")
+	}
+	var analysisResponse *AnalysisResponse
+	name, err := multiClient.Submit(ctx, func(ctx context.Context, client *Client) error {
+		if _, err := fileAnalysisParams.File.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		var err error
+		analysisResponse, err = client.CreateFileAnalysis(ctx, fileAnalysisParams)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &InstanceAnalysisResponse{Instance: name, AnalysisResponse: *analysisResponse}, nil
+}
+
+// FanOut calls read on every instance concurrently and returns the error of each failed instance, nil when none failed.
+// Once ctx is done no instance is called anymore, the ones left fail with the context error.
+func (multiClient *MultiClient) FanOut(ctx context.Context, read func(ctx context.Context, instance Instance) error) error {
+	errs := make([]error, len(multiClient.instances))
+	started := runConcurrently(ctx, len(multiClient.instances), multiClient.options.Concurrency, func(ctx context.Context, index int) {
+		instance := multiClient.instances[index]
+		err := read(ctx, instance)
+		multiClient.record(instance.Name, err)
+		errs[index] = err
+	})
+	// * the instances skipped because ctx was done were not called so their health is left untouched
+	for index := started; index < len(errs); index++ {
+		errs[index] = ctx.Err()
+	}
+	fanOutError := &FanOutError{Errors: map[string]error{}}
+	for index, err := range errs {
+		if err != nil {
+			fanOutError.Errors[multiClient.instances[index].Name] = err
+		}
+	}
+	if len(fanOutError.Errors) == 0 {
+		return nil
+	}
+	return fanOutError
+}
+
+// SearchJobs fetches, on every instance, the jobs whose observable name or sample md5 is the given value, matched case-insensitively.
+// The jobs are merged newest first with the name of their instance. When some instances failed,
+// the jobs of the others are returned along with a *FanOutError.
+//
+//	Endpoint: GET /api/jobs?observable_name={observable}&page={page}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/jobs/operation/jobs_list
+func (multiClient *MultiClient) SearchJobs(ctx context.Context, observable string) ([]InstanceJob, error) {
+	jobQueries := []JobQuery{{ObservableName: observable}}
+	if md5Regex.MatchString(observable) {
+		jobQueries = append(jobQueries, JobQuery{Md5: observable})
+	}
+	var mutex sync.Mutex
+	found := []InstanceJob{}
+	err := multiClient.FanOut(ctx, func(ctx context.Context, instance Instance) error {
+		seen := map[int]bool{}
+		instanceJobs := []InstanceJob{}
+		for index := range jobQueries {
+			jobs, err := instance.Client.JobService.Search(ctx, &jobQueries[index])
+			if err != nil {
+				return err
+			}
+			// * ThreatMatrix matches substrings, only the jobs of this exact observable are kept
+			for _, job := range jobs {
+				if seen[job.ID] {
+					continue
+				}
+				if strings.EqualFold(job.ObservableName, observable) || (job.Md5 != "" && strings.EqualFold(job.Md5, observable)) {
+					seen[job.ID] = true
+					instanceJobs = append(instanceJobs, InstanceJob{Instance: instance.Name, JobList: job})
+				}
+			}
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		found = append(found, instanceJobs...)
+		return nil
+	})
+	sort.SliceStable(found, func(i, j int) bool {
+		iTime, jTime := found[i].ReceivedRequestTime, found[j].ReceivedRequestTime
+		if iTime != nil && jTime != nil && !iTime.Equal(*jTime) {
+			return iTime.After(*jTime)
+		}
+		if (iTime == nil) != (jTime == nil) {
+			return iTime != nil
+		}
+		if found[i].Instance != found[j].Instance {
+			return found[i].Instance < found[j].Instance
+		}
+		return found[i].ID > found[j].ID
+	})
+	return found, err
+}
+
+// CheckHealth queries every instance concurrently and returns their updated health.
+//
+//	Endpoint: GET /api/me/access
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/me/operation/me_access_retrieve
+func (multiClient *MultiClient) CheckHealth(ctx context.Context) []InstanceHealth {
+	multiClient.FanOut(ctx, func(ctx context.Context, instance Instance) error {
+		_, err := instance.Client.UserService.Access(ctx)
+		return err
+	})
+	return multiClient.Health()
+}
@@ -0,0 +1,341 @@
+package gothreatmatrix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/khulnasoft/go-threatmatrix/constants"
+)
+
+// PluginConfig represents a value of a plugin param or secret stored by ThreatMatrix for a user or an organization.
+type PluginConfig struct {
+	ID              uint64      `json:"id"`
+	Attribute       string      `json:"attribute"`
+	Value           interface{} `json:"value"`
+	Type            string      `json:"type"`
+	IsSecret        bool        `json:"is_secret"`
+	ForOrganization bool        `json:"for_organization"`
+	Organization    string      `json:"organization"`
+	Owner           string      `json:"owner"`
+	AnalyzerConfig  string      `json:"analyzer_config,omitempty"`
+	ConnectorConfig string      `json:"connector_config,omitempty"`
+	IngestorConfig  string      `json:"ingestor_config,omitempty"`
+}
+
+// pluginName returns the name of the plugin the value belongs to, for the given plugin type.
+func (pluginConfig *PluginConfig) pluginName(pluginType string) string {
+	switch pluginType {
+	case PluginTypeConnector:
+		return pluginConfig.ConnectorConfig
+	case PluginTypeIngestor:
+		return pluginConfig.IngestorConfig
+	}
+	return pluginConfig.AnalyzerConfig
+}
+
+// OrganizationPluginValue represents the value a param or secret of a plugin takes for the members of your organization.
+type OrganizationPluginValue struct {
+	Attribute string `json:"attribute"`
+	IsSecret  bool   `json:"is_secret"`
+	// Parameter is the declaration of the param, it is nil for secrets.
+	Parameter *Parameter `json:"parameter,omitempty"`
+	// Secret is the declaration of the secret, it is nil for params.
+	Secret *Secret `json:"secret,omitempty"`
+	// Value is the organization override when Overridden, the plugin default otherwise.
+	// The value of a secret is a SecretValue so it is never printed.
+	Value interface{} `json:"value"`
+	// Overridden is set when the value comes from the organization rather than being inherited from the plugin.
+	Overridden bool `json:"overridden"`
+	// ConfigID identifies the organization override, it is 0 for inherited values.
+	ConfigID uint64 `json:"config_id,omitempty"`
+}
+
+// OrganizationService handles communication with the organization-level plugin configuration of the ThreatMatrix API.
+// Organization values apply to every member of your organization and take precedence over the plugin defaults.
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/plugin-config
+type OrganizationService struct {
+	client *Client
+}
+
+// pluginConfigField returns the field naming a plugin of the given type in a plugin config.
+func pluginConfigField(pluginType string) (string, error) {
+	switch pluginType {
+	case PluginTypeAnalyzer:
+		return "analyzer_config", nil
+	case PluginTypeConnector:
+		return "connector_config", nil
+	case PluginTypeIngestor:
+		return "ingestor_config", nil
+	}
+	return "", fmt.Errorf("Unsupported plugin type %q", pluginType)
+}
+
+// pluginDeclaration fetches the params and secrets declared by a plugin.
+func (organizationService *OrganizationService) pluginDeclaration(ctx context.Context, pluginType string, pluginName string) (*BaseConfigurationType, error) {
+	switch pluginType {
+	case PluginTypeAnalyzer:
+		analyzerConfig, err := organizationService.client.AnalyzerService.Get(ctx, pluginName)
+		if err != nil {
+			return nil, err
+		}
+		return &analyzerConfig.BaseConfigurationType, nil
+	case PluginTypeConnector:
+		connectorConfig, err := organizationService.client.ConnectorService.Get(ctx, pluginName)
+		if err != nil {
+			return nil, err
+		}
+		return &connectorConfig.BaseConfigurationType, nil
+	case PluginTypeIngestor:
+		ingestorConfig, err := organizationService.client.IngestorService.Get(ctx, pluginName)
+		if err != nil {
+			return nil, err
+		}
+		return &ingestorConfig.BaseConfigurationType, nil
+	}
+	return nil, fmt.Errorf("Unsupported plugin type %q", pluginType)
+}
+
+// ListPluginConfigs fetches every plugin value stored for you and your organization.
+//
+//	Endpoint: GET /api/plugin-config
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/plugin-config/operation/plugin_config_list
+func (organizationService *OrganizationService) ListPluginConfigs(ctx context.Context) ([]PluginConfig, error) {
+	requestUrl := organizationService.client.options.Url + constants.PLUGIN_CONFIG_URL
+	contentType := constants.ContentTypeJSON
+	method := http.MethodGet
+	request, err := organizationService.client.buildRequest(ctx, method, contentType, nil, requestUrl)
+	if err != nil {
+		return nil, err
+	}
+	pluginConfigs := []PluginConfig{}
+	successResp, err := organizationService.client.newRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if unmarshalError := json.Unmarshal(successResp.Data, &pluginConfigs); unmarshalError != nil {
+		return nil, unmarshalError
+	}
+	return pluginConfigs, nil
+}
+
+// organizationOverrides returns the organization values of a plugin indexed by attribute.
+func (organizationService *OrganizationService) organizationOverrides(ctx context.Context, pluginType string, pluginName string) (map[string]PluginConfig, error) {
+	pluginConfigs, err := organizationService.ListPluginConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	overrides := map[string]PluginConfig{}
+	for _, pluginConfig := range pluginConfigs {
+		if pluginConfig.ForOrganization && pluginConfig.pluginName(pluginType) == pluginName {
+			overrides[pluginConfig.Attribute] = pluginConfig
+		}
+	}
+	return overrides, nil
+}
+
+// PluginValues returns every param and secret of a plugin with the value your organization uses, sorted by attribute.
+// Each value tells whether it is inherited from the plugin defaults or overridden by the organization.
+// pluginType is either PluginTypeAnalyzer, PluginTypeConnector or PluginTypeIngestor.
+//
+//	Endpoint: GET /api/plugin-config
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/plugin-config/operation/plugin_config_list
+func (organizationService *OrganizationService) PluginValues(ctx context.Context, pluginType string, pluginName string) ([]OrganizationPluginValue, error) {
+	declaration, err := organizationService.pluginDeclaration(ctx, pluginType, pluginName)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := organizationService.organizationOverrides(ctx, pluginType, pluginName)
+	if err != nil {
+		return nil, err
+	}
+	pluginValues := []OrganizationPluginValue{}
+	for name, parameter := range declaration.Params {
+		parameter := parameter
+		pluginValue := OrganizationPluginValue{
+			Attribute: name,
+			Parameter: &parameter,
+			Value:     parameter.Value,
+		}
+		if override, ok := overrides[name]; ok {
+			pluginValue.Value = override.Value
+			pluginValue.Overridden = true
+			pluginValue.ConfigID = override.ID
+		}
+		pluginValues = append(pluginValues, pluginValue)
+	}
+	for name, secret := range declaration.Secrets {
+		secret := secret
+		pluginValue := OrganizationPluginValue{
+			Attribute: name,
+			IsSecret:  true,
+			Secret:    &secret,
+		}
+		if override, ok := overrides[name]; ok {
+			// * secrets have no default, only an organization value
+			pluginValue.Value = SecretValue(fmt.Sprint(override.Value))
+			pluginValue.Overridden = true
+			pluginValue.ConfigID = override.ID
+		}
+		pluginValues = append(pluginValues, pluginValue)
+	}
+	sort.Slice(pluginValues, func(i, j int) bool {
+		return pluginValues[i].Attribute < pluginValues[j].Attribute
+	})
+	return pluginValues, nil
+}
+
+// updatePluginConfig changes the value of an existing organization override.
+// The value of a secret is redacted from the returned error.
+//
+//	Endpoint: PATCH /api/plugin-config/{id}
+func (organizationService *OrganizationService) updatePluginConfig(ctx context.Context, configId uint64, value interface{}, secrets []string) error {
+	route := organizationService.client.options.Url + constants.SPECIFIC_PLUGIN_CONFIG_URL
+	requestUrl := fmt.Sprintf(route, configId)
+	contentType := constants.ContentTypeJSON
+	method := http.MethodPatch
+	valueJson, err := json.Marshal(map[string]interface{}{"value": value})
+	if err != nil {
+		return err
+	}
+	request, err := organizationService.client.buildRequest(ctx, method, contentType, bytes.NewBuffer(valueJson), requestUrl)
+	if err != nil {
+		return err
+	}
+	if _, err := organizationService.client.newRequest(ctx, request); err != nil {
+		return redactSecrets(err, secrets)
+	}
+	return nil
+}
+
+// setValues updates the attributes the organization already overrides and creates overrides for the other ones.
+// It is shared by every method setting organization-level values so an override is never posted twice.
+func (organizationService *OrganizationService) setValues(ctx context.Context, pluginType string, pluginName string, values map[string]interface{}, secrets []string) error {
+	pluginField, err := pluginConfigField(pluginType)
+	if err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return errors.New("At least one value is required")
+	}
+	overrides, err := organizationService.organizationOverrides(ctx, pluginType, pluginName)
+	if err != nil {
+		return err
+	}
+	defer organizationService.client.ConfigCache.Invalidate()
+	newValues := map[string]interface{}{}
+	for _, attribute := range sortedKeys(values) {
+		override, ok := overrides[attribute]
+		if !ok || override.ID == 0 {
+			newValues[attribute] = values[attribute]
+			continue
+		}
+		if err := organizationService.updatePluginConfig(ctx, override.ID, values[attribute], secrets); err != nil {
+			return err
+		}
+	}
+	if len(newValues) == 0 {
+		return nil
+	}
+	return organizationService.client.setPluginConfigValues(ctx, pluginField, pluginName, newValues, secrets)
+}
+
+// SetParams overrides the params of a plugin for every member of your organization.
+// Every param must be declared by the plugin, the params already overridden are updated and the resulting values are returned.
+//
+//	Endpoint: POST /api/plugin-config
+//	Endpoint: PATCH /api/plugin-config/{id}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/plugin-config/operation/plugin_config_create
+func (organizationService *OrganizationService) SetParams(ctx context.Context, pluginType string, pluginName string, params map[string]interface{}) ([]OrganizationPluginValue, error) {
+	if _, err := pluginConfigField(pluginType); err != nil {
+		return nil, err
+	}
+	if len(params) == 0 {
+		return nil, errors.New("At least one value is required")
+	}
+	declaration, err := organizationService.pluginDeclaration(ctx, pluginType, pluginName)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPluginAttributes(pluginName, "params", declaration.paramNames(), sortedKeys(params)); err != nil {
+		return nil, err
+	}
+	if err := organizationService.setValues(ctx, pluginType, pluginName, params, nil); err != nil {
+		return nil, err
+	}
+	return organizationService.PluginValues(ctx, pluginType, pluginName)
+}
+
+// SetSecrets overrides the secrets of a plugin for every member of your organization.
+// Every secret must be declared by the plugin, the secrets already overridden are updated.
+// The values are never logged and are redacted from the returned errors.
+//
+//	Endpoint: POST /api/plugin-config
+//	Endpoint: PATCH /api/plugin-config/{id}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/plugin-config/operation/plugin_config_create
+func (organizationService *OrganizationService) SetSecrets(ctx context.Context, pluginType string, pluginName string, secrets map[string]SecretValue) ([]OrganizationPluginValue, error) {
+	if _, err := pluginConfigField(pluginType); err != nil {
+		return nil, err
+	}
+	if len(secrets) == 0 {
+		return nil, errors.New("At least one value is required")
+	}
+	declaration, err := organizationService.pluginDeclaration(ctx, pluginType, pluginName)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPluginAttributes(pluginName, "secrets", declaration.secretNames(), secretKeys(secrets)); err != nil {
+		return nil, err
+	}
+	values, plainValues := revealSecrets(secrets)
+	if err := organizationService.setValues(ctx, pluginType, pluginName, values, plainValues); err != nil {
+		return nil, err
+	}
+	return organizationService.PluginValues(ctx, pluginType, pluginName)
+}
+
+// Unset removes the organization override of a param or secret, the param falls back to the plugin default.
+// It returns false when the organization did not override the attribute or the server did not return the override id.
+//
+//	Endpoint: DELETE /api/plugin-config/{id}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/plugin-config/operation/plugin_config_destroy
+func (organizationService *OrganizationService) Unset(ctx context.Context, pluginType string, pluginName string, attribute string) (bool, error) {
+	if _, err := pluginConfigField(pluginType); err != nil {
+		return false, err
+	}
+	overrides, err := organizationService.organizationOverrides(ctx, pluginType, pluginName)
+	if err != nil {
+		return false, err
+	}
+	override, ok := overrides[attribute]
+	if !ok || override.ID == 0 {
+		return false, nil
+	}
+	route := organizationService.client.options.Url + constants.SPECIFIC_PLUGIN_CONFIG_URL
+	requestUrl := fmt.Sprintf(route, override.ID)
+	contentType := constants.ContentTypeJSON
+	method := http.MethodDelete
+	request, err := organizationService.client.buildRequest(ctx, method, contentType, nil, requestUrl)
+	if err != nil {
+		return false, err
+	}
+	defer organizationService.client.ConfigCache.Invalidate()
+	successResp, err := organizationService.client.newRequest(ctx, request)
+	if err != nil {
+		return false, err
+	}
+	if successResp.StatusCode == http.StatusNoContent {
+		return true, nil
+	}
+	return false, nil
+}
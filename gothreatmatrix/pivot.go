@@ -0,0 +1,235 @@
+package gothreatmatrix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/khulnasoft/go-threatmatrix/constants"
+)
+
+// defaultPivotTreeDepth is how deep PivotService.InvestigationTree walks when no depth is given.
+const defaultPivotTreeDepth = 10
+
+// PivotConfig represents how a pivot is configured in ThreatMatrix.
+// A pivot starts a playbook on a value extracted from the reports of its related analyzers or connectors.
+//
+// ThreatMatrix docs: https://threatmatrix.readthedocs.io/en/latest/Usage.html#pivots
+type PivotConfig struct {
+	BaseConfigurationType
+	RelatedAnalyzerConfigs  []string `json:"related_analyzer_configs"`
+	RelatedConnectorConfigs []string `json:"related_connector_configs"`
+	PlaybooksChoice         []string `json:"playbooks_choice"`
+}
+
+// PivotListResponse represents a page of pivot configurations.
+type PivotListResponse struct {
+	Count      int           `json:"count"`
+	TotalPages int           `json:"total_pages"`
+	Results    []PivotConfig `json:"results"`
+}
+
+// PivotMap represents a job started by a pivot: EndingJob was spawned from StartingJob by PivotConfig.
+type PivotMap struct {
+	ID          uint64 `json:"id"`
+	StartingJob uint64 `json:"starting_job"`
+	PivotConfig string `json:"pivot_config"`
+	EndingJob   uint64 `json:"ending_job"`
+}
+
+// PivotMapListResponse represents a page of pivot maps.
+type PivotMapListResponse struct {
+	Count      int        `json:"count"`
+	TotalPages int        `json:"total_pages"`
+	Results    []PivotMap `json:"results"`
+}
+
+// PivotTree represents a job and, recursively, every job its pivots spawned.
+type PivotTree struct {
+	Job *Job `json:"job"`
+	// Pivot is the name of the pivot that spawned this job, it is empty for the starting job.
+	Pivot    string      `json:"pivot,omitempty"`
+	Children []PivotTree `json:"children"`
+	// Truncated is set when the children were not fetched because the maximum depth was reached.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// PivotService handles communication with pivot related methods of the ThreatMatrix API.
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/pivot
+type PivotService struct {
+	client *Client
+}
+
+// ListAll fetches every page of pivot configurations in your ThreatMatrix instance.
+//
+//	Endpoint: GET /api/pivot?page={page}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/pivot/operation/pivot_list
+func (pivotService *PivotService) ListAll(ctx context.Context) ([]PivotConfig, error) {
+	pivots := []PivotConfig{}
+	for page := 1; ; page++ {
+		requestUrl := fmt.Sprintf("%s%s?page=%d", pivotService.client.options.Url, constants.PIVOT_CONFIG_URL, page)
+		contentType := constants.ContentTypeJSON
+		method := http.MethodGet
+		request, err := pivotService.client.buildRequest(ctx, method, contentType, nil, requestUrl)
+		if err != nil {
+			return nil, err
+		}
+		successResp, err := pivotService.client.newRequest(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		pivotList := PivotListResponse{}
+		if unmarshalError := json.Unmarshal(successResp.Data, &pivotList); unmarshalError != nil {
+			return nil, unmarshalError
+		}
+		pivots = append(pivots, pivotList.Results...)
+		if page >= pivotList.TotalPages || len(pivotList.Results) == 0 {
+			return pivots, nil
+		}
+	}
+}
+
+// Get fetches the configuration of the specified pivot.
+//
+//	Endpoint: GET /api/pivot/{NameOfPivot}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/pivot/operation/pivot_retrieve
+func (pivotService *PivotService) Get(ctx context.Context, pivotName string) (*PivotConfig, error) {
+	pivotConfig := PivotConfig{}
+	if err := pivotService.client.getPluginConfig(ctx, constants.SPECIFIC_PIVOT_CONFIG_URL, pivotName, &pivotConfig); err != nil {
+		return nil, err
+	}
+	return &pivotConfig, nil
+}
+
+// HealthCheck checks if the specified pivot is up and running
+//
+//	Endpoint: GET /api/pivot/{NameOfPivot}/healthcheck
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/pivot/operation/pivot_healthcheck_retrieve
+func (pivotService *PivotService) HealthCheck(ctx context.Context, pivotName string) (bool, error) {
+	route := pivotService.client.options.Url + constants.PIVOT_HEALTHCHECK_URL
+	requestUrl := fmt.Sprintf(route, pivotName)
+	contentType := constants.ContentTypeJSON
+	method := http.MethodGet
+	request, err := pivotService.client.buildRequest(ctx, method, contentType, nil, requestUrl)
+	if err != nil {
+		return false, err
+	}
+	status := StatusResponse{}
+	successResp, err := pivotService.client.newRequest(ctx, request)
+	if err != nil {
+		return false, err
+	}
+	if unmarshalError := json.Unmarshal(successResp.Data, &status); unmarshalError != nil {
+		return false, unmarshalError
+	}
+	return status.Status, nil
+}
+
+// Enable lets you enable a pivot.
+//
+//	Endpoint: PATCH /api/pivot/{NameOfPivot}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/pivot/operation/pivot_partial_update
+func (pivotService *PivotService) Enable(ctx context.Context, pivotName string) (*PivotConfig, error) {
+	pivotConfig := PivotConfig{}
+	if err := pivotService.client.setPluginDisabled(ctx, constants.SPECIFIC_PIVOT_CONFIG_URL, pivotName, false, &pivotConfig); err != nil {
+		return nil, err
+	}
+	return &pivotConfig, nil
+}
+
+// Disable lets you disable a pivot.
+//
+//	Endpoint: PATCH /api/pivot/{NameOfPivot}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/pivot/operation/pivot_partial_update
+func (pivotService *PivotService) Disable(ctx context.Context, pivotName string) (*PivotConfig, error) {
+	pivotConfig := PivotConfig{}
+	if err := pivotService.client.setPluginDisabled(ctx, constants.SPECIFIC_PIVOT_CONFIG_URL, pivotName, true, &pivotConfig); err != nil {
+		return nil, err
+	}
+	return &pivotConfig, nil
+}
+
+// ListPivotMaps fetches every job the pivots of the specified job spawned.
+//
+//	Endpoint: GET /api/pivot_map?starting_job={jobID}&page={page}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/pivot_map/operation/pivot_map_list
+func (pivotService *PivotService) ListPivotMaps(ctx context.Context, jobId uint64) ([]PivotMap, error) {
+	pivotMaps := []PivotMap{}
+	for page := 1; ; page++ {
+		requestUrl := fmt.Sprintf("%s%s?starting_job=%d&page=%d", pivotService.client.options.Url, constants.PIVOT_MAP_URL, jobId, page)
+		contentType := constants.ContentTypeJSON
+		method := http.MethodGet
+		request, err := pivotService.client.buildRequest(ctx, method, contentType, nil, requestUrl)
+		if err != nil {
+			return nil, err
+		}
+		successResp, err := pivotService.client.newRequest(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		pivotMapList := PivotMapListResponse{}
+		if unmarshalError := json.Unmarshal(successResp.Data, &pivotMapList); unmarshalError != nil {
+			return nil, unmarshalError
+		}
+		pivotMaps = append(pivotMaps, pivotMapList.Results...)
+		if page >= pivotMapList.TotalPages || len(pivotMapList.Results) == 0 {
+			return pivotMaps, nil
+		}
+	}
+}
+
+// InvestigationTree walks from the specified job to every job its pivots spawned, recursively, and fetches each of them.
+// maxDepth limits how many pivots are followed from the starting job, it defaults to 10 when it is 0 or less.
+// A job reached twice is only expanded the first time so cycles end the walk.
+func (pivotService *PivotService) InvestigationTree(ctx context.Context, jobId uint64, maxDepth int) (*PivotTree, error) {
+	if maxDepth <= 0 {
+		maxDepth = defaultPivotTreeDepth
+	}
+	visited := map[uint64]bool{}
+	return pivotService.pivotTree(ctx, jobId, "", maxDepth, visited)
+}
+
+// pivotTree fetches a job and the subtree of the jobs it pivoted into.
+func (pivotService *PivotService) pivotTree(ctx context.Context, jobId uint64, pivotName string, depth int, visited map[uint64]bool) (*PivotTree, error) {
+	job, err := pivotService.client.JobService.Get(ctx, jobId)
+	if err != nil {
+		return nil, err
+	}
+	tree := &PivotTree{
+		Job:      job,
+		Pivot:    pivotName,
+		Children: []PivotTree{},
+	}
+	if visited[jobId] {
+		return tree, nil
+	}
+	visited[jobId] = true
+	pivotMaps, err := pivotService.ListPivotMaps(ctx, jobId)
+	if err != nil {
+		return nil, err
+	}
+	if depth == 0 {
+		tree.Truncated = len(pivotMaps) > 0
+		return tree, nil
+	}
+	sort.Slice(pivotMaps, func(i, j int) bool {
+		return pivotMaps[i].EndingJob < pivotMaps[j].EndingJob
+	})
+	for _, pivotMap := range pivotMaps {
+		child, err := pivotService.pivotTree(ctx, pivotMap.EndingJob, pivotMap.PivotConfig, depth-1, visited)
+		if err != nil {
+			return nil, err
+		}
+		tree.Children = append(tree.Children, *child)
+	}
+	return tree, nil
+}
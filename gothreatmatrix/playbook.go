@@ -1,9 +1,14 @@
 package gothreatmatrix
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/khulnasoft/go-threatmatrix/constants"
 )
@@ -11,6 +16,7 @@ import (
 type PlaybookConfig struct {
 	ID                   int64                  `json:"id"`
 	Name                 string                 `json:"name"`
+	Description          string                 `json:"description"`
 	Type                 []string               `json:"type"` // ChoiceArrayField equivalent
 	Analyzers            []string               `json:"analyzers"`
 	Connectors           []string               `json:"connectors"`
@@ -33,6 +39,11 @@ type PlaybookListResponse struct {
 
 type PlaybookService struct {
 	client *Client
+	// CacheTTL is how long CachedPlaybooks keeps the playbook list, it defaults to DefaultPlaybookCacheTTL.
+	CacheTTL        time.Duration
+	cacheMutex      sync.Mutex
+	cachedPlaybooks []PlaybookConfig
+	cachedAt        time.Time
 }
 
 func (playbookService *PlaybookService) ListPlaybooks(ctx context.Context) (*PlaybookListResponse, error) {
@@ -76,3 +87,301 @@ func (playbookService *PlaybookService) GetPlaybookByName(ctx context.Context, p
 
 	return &playbook, nil
 }
+
+// PlaybookValidationError is returned when a playbook references plugins that don't exist on the instance.
+type PlaybookValidationError struct {
+	UnknownAnalyzers  []string
+	UnknownConnectors []string
+	UnknownPivots     []string
+}
+
+// Error lets you implement the error interface.
+func (validationError *PlaybookValidationError) Error() string {
+	unknownPlugins := []string{}
+	if len(validationError.UnknownAnalyzers) > 0 {
+		unknownPlugins = append(unknownPlugins, "analyzers: "+strings.Join(validationError.UnknownAnalyzers, ", "))
+	}
+	if len(validationError.UnknownConnectors) > 0 {
+		unknownPlugins = append(unknownPlugins, "connectors: "+strings.Join(validationError.UnknownConnectors, ", "))
+	}
+	if len(validationError.UnknownPivots) > 0 {
+		unknownPlugins = append(unknownPlugins, "pivots: "+strings.Join(validationError.UnknownPivots, ", "))
+	}
+	return fmt.Sprintf("Playbook references unknown plugins (%s)", strings.Join(unknownPlugins, "; "))
+}
+
+// unknownNames returns the names that are not in known.
+func unknownNames(names []string, known map[string]bool) []string {
+	var unknown []string
+	for _, name := range names {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
+}
+
+// playbookPlugins holds the names of the plugins known to your ThreatMatrix instance,
+// each kind is fetched the first time a playbook references it so a run of validations fetches it once.
+type playbookPlugins struct {
+	analyzers  map[string]bool
+	connectors map[string]bool
+	pivots     map[string]bool
+}
+
+// analyzerNames returns the names of the analyzers, fetching them on first use.
+func (plugins *playbookPlugins) analyzerNames(ctx context.Context, client *Client) (map[string]bool, error) {
+	if plugins.analyzers == nil {
+		analyzerConfigs, err := client.AnalyzerService.GetConfigsByName(ctx)
+		if err != nil {
+			return nil, err
+		}
+		plugins.analyzers = map[string]bool{}
+		for analyzerName := range analyzerConfigs {
+			plugins.analyzers[analyzerName] = true
+		}
+	}
+	return plugins.analyzers, nil
+}
+
+// connectorNames returns the names of the connectors, fetching them on first use.
+func (plugins *playbookPlugins) connectorNames(ctx context.Context, client *Client) (map[string]bool, error) {
+	if plugins.connectors == nil {
+		connectorConfigs, err := client.ConnectorService.GetConfigsByName(ctx)
+		if err != nil {
+			return nil, err
+		}
+		plugins.connectors = map[string]bool{}
+		for connectorName := range connectorConfigs {
+			plugins.connectors[connectorName] = true
+		}
+	}
+	return plugins.connectors, nil
+}
+
+// pivotNames returns the names of the pivots, fetching them on first use.
+func (plugins *playbookPlugins) pivotNames(ctx context.Context, client *Client) (map[string]bool, error) {
+	if plugins.pivots == nil {
+		pivotConfigs, err := client.PivotService.ListAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		plugins.pivots = map[string]bool{}
+		for _, pivotConfig := range pivotConfigs {
+			plugins.pivots[pivotConfig.Name] = true
+		}
+	}
+	return plugins.pivots, nil
+}
+
+// Validate checks that every analyzer, connector and pivot referenced by the playbook exists on your ThreatMatrix instance.
+// It returns a *PlaybookValidationError listing the unknown ones.
+func (playbookService *PlaybookService) Validate(ctx context.Context, playbookConfig *PlaybookConfig) error {
+	return playbookService.validate(ctx, playbookConfig, &playbookPlugins{})
+}
+
+// validate checks a playbook against the plugins, fetching the kinds it needs that are not known yet.
+func (playbookService *PlaybookService) validate(ctx context.Context, playbookConfig *PlaybookConfig, plugins *playbookPlugins) error {
+	if strings.TrimSpace(playbookConfig.Name) == "" {
+		return fmt.Errorf("Playbook name cannot be empty")
+	}
+	client := playbookService.client
+	validationError := &PlaybookValidationError{}
+	if len(playbookConfig.Analyzers) > 0 {
+		analyzerNames, err := plugins.analyzerNames(ctx, client)
+		if err != nil {
+			return err
+		}
+		validationError.UnknownAnalyzers = unknownNames(playbookConfig.Analyzers, analyzerNames)
+	}
+	if len(playbookConfig.Connectors) > 0 {
+		connectorNames, err := plugins.connectorNames(ctx, client)
+		if err != nil {
+			return err
+		}
+		validationError.UnknownConnectors = unknownNames(playbookConfig.Connectors, connectorNames)
+	}
+	if len(playbookConfig.Pivots) > 0 {
+		pivotNames, err := plugins.pivotNames(ctx, client)
+		if err != nil {
+			return err
+		}
+		validationError.UnknownPivots = unknownNames(playbookConfig.Pivots, pivotNames)
+	}
+	if len(validationError.UnknownAnalyzers) > 0 || len(validationError.UnknownConnectors) > 0 || len(validationError.UnknownPivots) > 0 {
+		return validationError
+	}
+	return nil
+}
+
+// playbookBody marshals the writable fields of a playbook, the ID and the owner are set by the server.
+func playbookBody(playbookConfig *PlaybookConfig) (*bytes.Buffer, error) {
+	playbookJson, err := json.Marshal(playbookConfig)
+	if err != nil {
+		return nil, err
+	}
+	playbookFields := map[string]interface{}{}
+	if err := json.Unmarshal(playbookJson, &playbookFields); err != nil {
+		return nil, err
+	}
+	delete(playbookFields, "id")
+	delete(playbookFields, "owner")
+	playbookJson, err = json.Marshal(playbookFields)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewBuffer(playbookJson), nil
+}
+
+// sendPlaybook sends a playbook body and decodes the playbook returned by the server.
+// The cached playbook list is dropped once the server accepted the change.
+func (playbookService *PlaybookService) sendPlaybook(ctx context.Context, method string, requestUrl string, body *bytes.Buffer) (*PlaybookConfig, error) {
+	contentType := constants.ContentTypeJSON
+	request, err := playbookService.client.buildRequest(ctx, method, contentType, body, requestUrl)
+	if err != nil {
+		return nil, err
+	}
+	successResp, err := playbookService.client.newRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	playbookService.InvalidateCache()
+	playbook := PlaybookConfig{}
+	if unmarshalError := json.Unmarshal(successResp.Data, &playbook); unmarshalError != nil {
+		return nil, unmarshalError
+	}
+	return &playbook, nil
+}
+
+// Create lets you create a new playbook once Validate has checked its plugins exist.
+//
+//	Endpoint: POST /api/playbook
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/playbook/operation/playbook_create
+func (playbookService *PlaybookService) Create(ctx context.Context, playbookConfig *PlaybookConfig) (*PlaybookConfig, error) {
+	if err := playbookService.Validate(ctx, playbookConfig); err != nil {
+		return nil, err
+	}
+	return playbookService.create(ctx, playbookConfig)
+}
+
+// create sends a playbook that was already validated.
+func (playbookService *PlaybookService) create(ctx context.Context, playbookConfig *PlaybookConfig) (*PlaybookConfig, error) {
+	body, err := playbookBody(playbookConfig)
+	if err != nil {
+		return nil, err
+	}
+	requestUrl := playbookService.client.options.Url + constants.BASE_PLAYBOOK_URL
+	return playbookService.sendPlaybook(ctx, http.MethodPost, requestUrl, body)
+}
+
+// Update lets you edit a playbook through its name once Validate has checked its plugins exist.
+// Every field of playbookConfig is sent, so start from the playbook returned by GetPlaybookByName.
+//
+//	Endpoint: PATCH /api/playbook/{name}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/playbook/operation/playbook_partial_update
+func (playbookService *PlaybookService) Update(ctx context.Context, playbookName string, playbookConfig *PlaybookConfig) (*PlaybookConfig, error) {
+	if err := playbookService.Validate(ctx, playbookConfig); err != nil {
+		return nil, err
+	}
+	return playbookService.update(ctx, playbookName, playbookConfig)
+}
+
+// update sends every field of a playbook that was already validated.
+func (playbookService *PlaybookService) update(ctx context.Context, playbookName string, playbookConfig *PlaybookConfig) (*PlaybookConfig, error) {
+	body, err := playbookBody(playbookConfig)
+	if err != nil {
+		return nil, err
+	}
+	route := playbookService.client.options.Url + constants.SPECIFIC_PLAYBOOK_URL
+	requestUrl := fmt.Sprintf(route, playbookName)
+	return playbookService.sendPlaybook(ctx, http.MethodPatch, requestUrl, body)
+}
+
+// patchPlaybook updates only the given fields of a playbook.
+func (playbookService *PlaybookService) patchPlaybook(ctx context.Context, playbookName string, fields map[string]interface{}) (*PlaybookConfig, error) {
+	fieldsJson, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	route := playbookService.client.options.Url + constants.SPECIFIC_PLAYBOOK_URL
+	requestUrl := fmt.Sprintf(route, playbookName)
+	return playbookService.sendPlaybook(ctx, http.MethodPatch, requestUrl, bytes.NewBuffer(fieldsJson))
+}
+
+// setDisabled flips the Disabled field of a playbook, leaving its Starting field untouched.
+// No request is made when the playbook is already in the wanted state.
+func (playbookService *PlaybookService) setDisabled(ctx context.Context, playbookName string, disabled bool) (*PlaybookConfig, error) {
+	playbook, err := playbookService.GetPlaybookByName(ctx, playbookName)
+	if err != nil {
+		return nil, err
+	}
+	if playbook.Disabled == disabled {
+		return playbook, nil
+	}
+	return playbookService.patchPlaybook(ctx, playbookName, map[string]interface{}{"disabled": disabled})
+}
+
+// Enable lets you enable a disabled playbook.
+//
+//	Endpoint: PATCH /api/playbook/{name}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/playbook/operation/playbook_partial_update
+func (playbookService *PlaybookService) Enable(ctx context.Context, playbookName string) (*PlaybookConfig, error) {
+	return playbookService.setDisabled(ctx, playbookName, false)
+}
+
+// Disable lets you disable a playbook so it can't be run anymore.
+//
+//	Endpoint: PATCH /api/playbook/{name}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/playbook/operation/playbook_partial_update
+func (playbookService *PlaybookService) Disable(ctx context.Context, playbookName string) (*PlaybookConfig, error) {
+	return playbookService.setDisabled(ctx, playbookName, true)
+}
+
+// SetStarting lets you choose whether a playbook can be used to start an analysis.
+// A disabled playbook can't be made starting.
+//
+//	Endpoint: PATCH /api/playbook/{name}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/playbook/operation/playbook_partial_update
+func (playbookService *PlaybookService) SetStarting(ctx context.Context, playbookName string, starting bool) (*PlaybookConfig, error) {
+	playbook, err := playbookService.GetPlaybookByName(ctx, playbookName)
+	if err != nil {
+		return nil, err
+	}
+	if starting && playbook.Disabled {
+		return nil, fmt.Errorf("Playbook %s is disabled and can't be made starting", playbookName)
+	}
+	if playbook.Starting == starting {
+		return playbook, nil
+	}
+	return playbookService.patchPlaybook(ctx, playbookName, map[string]interface{}{"starting": starting})
+}
+
+// Delete removes the given playbook from your ThreatMatrix instance.
+//
+//	Endpoint: DELETE /api/playbook/{name}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/playbook/operation/playbook_destroy
+func (playbookService *PlaybookService) Delete(ctx context.Context, playbookName string) (bool, error) {
+	route := playbookService.client.options.Url + constants.SPECIFIC_PLAYBOOK_URL
+	requestUrl := fmt.Sprintf(route, playbookName)
+	contentType := constants.ContentTypeJSON
+	method := http.MethodDelete
+	request, err := playbookService.client.buildRequest(ctx, method, contentType, nil, requestUrl)
+	if err != nil {
+		return false, err
+	}
+	successResp, err := playbookService.client.newRequest(ctx, request)
+	if err != nil {
+		return false, err
+	}
+	if successResp.StatusCode == http.StatusNoContent {
+		playbookService.InvalidateCache()
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,241 @@
+package gothreatmatrix
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// These represent the observable classifications used by ThreatMatrix, FileClassification is the type of playbooks analyzing files.
+const (
+	IPClassification      = "ip"
+	URLClassification     = "url"
+	DomainClassification  = "domain"
+	HashClassification    = "hash"
+	GenericClassification = "generic"
+	FileClassification    = "file"
+)
+
+// DefaultPlaybookCacheTTL is how long the playbook list is cached when PlaybookService.CacheTTL is not set.
+const DefaultPlaybookCacheTTL = 5 * time.Minute
+
+var (
+	domainRegex = regexp.MustCompile(`^(?i)([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}\.?$`)
+	hashRegex   = regexp.MustCompile(`^(?i)([0-9a-f]{32}|[0-9a-f]{40}|[0-9a-f]{64}|[0-9a-f]{128})$`)
+)
+
+// ClassifyObservable guesses the ThreatMatrix classification of an observable: ip, url, domain, hash or generic.
+func ClassifyObservable(observable string) string {
+	observable = strings.TrimSpace(observable)
+	if net.ParseIP(observable) != nil {
+		return IPClassification
+	}
+	if parsedUrl, err := url.Parse(observable); err == nil && parsedUrl.Scheme != "" && parsedUrl.Host != "" {
+		return URLClassification
+	}
+	if hashRegex.MatchString(observable) {
+		return HashClassification
+	}
+	if domainRegex.MatchString(observable) {
+		return DomainClassification
+	}
+	return GenericClassification
+}
+
+// copyStrings returns a copy of a slice of strings, keeping nil as nil.
+func copyStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	return append([]string{}, values...)
+}
+
+// copyValue returns a deep copy of a decoded JSON value.
+func copyValue(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		return copyValues(typedValue)
+	case []interface{}:
+		valuesCopy := make([]interface{}, len(typedValue))
+		for index, item := range typedValue {
+			valuesCopy[index] = copyValue(item)
+		}
+		return valuesCopy
+	}
+	return value
+}
+
+// copyValues returns a deep copy of a decoded JSON object, keeping nil as nil.
+func copyValues(values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+	valuesCopy := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		valuesCopy[key] = copyValue(value)
+	}
+	return valuesCopy
+}
+
+// copyPlaybooks returns a deep copy of playbook configurations so callers never share the cached ones.
+func copyPlaybooks(playbooks []PlaybookConfig) []PlaybookConfig {
+	playbooksCopy := make([]PlaybookConfig, len(playbooks))
+	for index, playbook := range playbooks {
+		playbook.Type = copyStrings(playbook.Type)
+		playbook.Analyzers = copyStrings(playbook.Analyzers)
+		playbook.Connectors = copyStrings(playbook.Connectors)
+		playbook.Pivots = copyStrings(playbook.Pivots)
+		playbook.Tags = copyStrings(playbook.Tags)
+		playbook.RuntimeConfiguration = copyValues(playbook.RuntimeConfiguration)
+		playbooksCopy[index] = playbook
+	}
+	return playbooksCopy
+}
+
+// CachedPlaybooks returns every playbook of your ThreatMatrix instance, fetching them again once CacheTTL has passed.
+// The returned playbooks are the caller's own, editing them leaves the cache untouched.
+func (playbookService *PlaybookService) CachedPlaybooks(ctx context.Context) ([]PlaybookConfig, error) {
+	playbookService.cacheMutex.Lock()
+	defer playbookService.cacheMutex.Unlock()
+	ttl := playbookService.CacheTTL
+	if ttl <= 0 {
+		ttl = DefaultPlaybookCacheTTL
+	}
+	if playbookService.cachedPlaybooks != nil && time.Since(playbookService.cachedAt) < ttl {
+		return copyPlaybooks(playbookService.cachedPlaybooks), nil
+	}
+	playbooks, err := playbookService.ListAllPlaybooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	playbookService.cachedPlaybooks = copyPlaybooks(playbooks)
+	playbookService.cachedAt = time.Now()
+	return playbooks, nil
+}
+
+// InvalidateCache drops the playbook list cached by CachedPlaybooks.
+func (playbookService *PlaybookService) InvalidateCache() {
+	playbookService.cacheMutex.Lock()
+	defer playbookService.cacheMutex.Unlock()
+	playbookService.cachedPlaybooks = nil
+}
+
+// PlaybookSelection represents the playbook chosen for an analysis and why it was chosen.
+type PlaybookSelection struct {
+	Playbook       PlaybookConfig
+	Classification string
+	// Reasons explains, in order, why every playbook was picked or skipped.
+	Reasons []string
+}
+
+// PlaybookSelectionParams represents the fields used to select a playbook and run the analysis.
+type PlaybookSelectionParams struct {
+	BasicAnalysisParams
+	// Preference lists playbook names in order of preference.
+	// Matching playbooks that are not listed come after, in alphabetical order.
+	Preference []string
+}
+
+// SelectPlaybook picks the playbook to run on an input of the given classification.
+// Disabled playbooks, playbooks that can't start an analysis and playbooks whose Type doesn't include the classification are skipped.
+func (playbookService *PlaybookService) SelectPlaybook(ctx context.Context, classification string, preference []string) (*PlaybookSelection, error) {
+	playbooks, err := playbookService.CachedPlaybooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rank := map[string]int{}
+	for index, playbookName := range preference {
+		if _, ok := rank[playbookName]; !ok {
+			rank[playbookName] = index
+		}
+	}
+	candidates := append([]PlaybookConfig{}, playbooks...)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		iRank, iPreferred := rank[candidates[i].Name]
+		jRank, jPreferred := rank[candidates[j].Name]
+		if iPreferred != jPreferred {
+			return iPreferred
+		}
+		if iPreferred {
+			return iRank < jRank
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+
+	selection := &PlaybookSelection{Classification: classification}
+	for _, playbook := range candidates {
+		switch {
+		case playbook.Disabled:
+			selection.Reasons = append(selection.Reasons, fmt.Sprintf("skipped %s: disabled", playbook.Name))
+		case !playbook.Starting:
+			selection.Reasons = append(selection.Reasons, fmt.Sprintf("skipped %s: not a starting playbook", playbook.Name))
+		case !containsString(playbook.Type, classification):
+			selection.Reasons = append(selection.Reasons, fmt.Sprintf("skipped %s: does not support %s", playbook.Name, classification))
+		default:
+			reason := fmt.Sprintf("picked %s: supports %s", playbook.Name, classification)
+			if playbookRank, ok := rank[playbook.Name]; ok {
+				reason += fmt.Sprintf(" and is preference #%d", playbookRank+1)
+			} else {
+				reason += " and no preferred playbook matched"
+			}
+			selection.Reasons = append(selection.Reasons, reason)
+			selection.Playbook = playbook
+			return selection, nil
+		}
+	}
+	return selection, fmt.Errorf("No enabled playbook supports %s", classification)
+}
+
+// containsString reports whether value is in values, case-insensitively.
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// AnalyzeWithBestPlaybook classifies an observable, picks the best playbook for it through PlaybookService.SelectPlaybook and submits it.
+// The selection is returned, even when the submission fails, so the choice can be audited.
+func (client *Client) AnalyzeWithBestPlaybook(ctx context.Context, observable string, params *PlaybookSelectionParams) (*PlaybookSelection, *MultipleAnalysisResponse, error) {
+	if params == nil {
+		params = &PlaybookSelectionParams{}
+	}
+	classification := ClassifyObservable(observable)
+	selection, err := client.PlaybookService.SelectPlaybook(ctx, classification, params.Preference)
+	if err != nil {
+		return selection, nil, err
+	}
+	analysisResponse, err := client.CreateObservablePlaybookAnalysis(ctx, &ObservablePlaybookAnalysisParams{
+		BasicAnalysisParams:      params.BasicAnalysisParams,
+		ObservableName:           strings.TrimSpace(observable),
+		ObservableClassification: classification,
+		PlaybookRequested:        selection.Playbook.Name,
+	})
+	return selection, analysisResponse, err
+}
+
+// AnalyzeFileWithBestPlaybook picks the best playbook for files through PlaybookService.SelectPlaybook and submits the file.
+// The selection is returned, even when the submission fails, so the choice can be audited.
+func (client *Client) AnalyzeFileWithBestPlaybook(ctx context.Context, file *os.File, params *PlaybookSelectionParams) (*PlaybookSelection, *MultipleAnalysisResponse, error) {
+	if params == nil {
+		params = &PlaybookSelectionParams{}
+	}
+	selection, err := client.PlaybookService.SelectPlaybook(ctx, FileClassification, params.Preference)
+	if err != nil {
+		return selection, nil, err
+	}
+	analysisResponse, err := client.CreateFilePlaybookAnalysis(ctx, &FilePlaybookAnalysisParams{
+		BasicAnalysisParams: params.BasicAnalysisParams,
+		PlaybookRequested:   selection.Playbook.Name,
+		File:                file,
+	})
+	return selection, analysisResponse, err
+}
@@ -0,0 +1,347 @@
+package gothreatmatrix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/khulnasoft/go-threatmatrix/constants"
+	"gopkg.in/yaml.v3"
+)
+
+// These represent the file formats used to store playbook definitions.
+const (
+	PlaybookFormatYAML = "yaml"
+	PlaybookFormatJSON = "json"
+)
+
+// PlaybookSyncOptions represents the optional fields of PlaybookService.Sync.
+type PlaybookSyncOptions struct {
+	// DryRun only computes the plan without applying it.
+	DryRun bool
+	// Prune deletes the playbooks that are not in the desired list.
+	Prune bool
+}
+
+// PlaybookChange represents a change to an existing playbook planned by PlaybookService.Sync.
+type PlaybookChange struct {
+	Name string
+	// Fields lists the JSON names of the fields that differ.
+	Fields  []string
+	Current PlaybookConfig
+	Desired PlaybookConfig
+}
+
+// PlaybookSyncPlan represents the changes needed to reconcile the server's playbooks with a desired list.
+type PlaybookSyncPlan struct {
+	Create  []PlaybookConfig
+	Update  []PlaybookChange
+	Delete  []PlaybookConfig
+	Applied bool
+}
+
+// String renders the plan the way it'd be reviewed in a dry run.
+func (plan *PlaybookSyncPlan) String() string {
+	lines := []string{}
+	for _, playbook := range plan.Create {
+		lines = append(lines, fmt.Sprintf("+ create %s", playbook.Name))
+	}
+	for _, change := range plan.Update {
+		lines = append(lines, fmt.Sprintf("~ update %s (%s)", change.Name, strings.Join(change.Fields, ", ")))
+	}
+	for _, playbook := range plan.Delete {
+		lines = append(lines, fmt.Sprintf("- delete %s", playbook.Name))
+	}
+	if len(lines) == 0 {
+		return "no changes"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ListAllPlaybooks fetches every page of playbooks in your ThreatMatrix instance.
+//
+//	Endpoint: GET /api/playbook?page={page}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/playbook/operation/playbook_list
+func (playbookService *PlaybookService) ListAllPlaybooks(ctx context.Context) ([]PlaybookConfig, error) {
+	playbooks := []PlaybookConfig{}
+	for page := 1; ; page++ {
+		requestUrl := fmt.Sprintf("%s%s?page=%d", playbookService.client.options.Url, constants.BASE_PLAYBOOK_URL, page)
+		contentType := constants.ContentTypeJSON
+		method := http.MethodGet
+		request, err := playbookService.client.buildRequest(ctx, method, contentType, nil, requestUrl)
+		if err != nil {
+			return nil, err
+		}
+		successResp, err := playbookService.client.newRequest(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		playbookList := PlaybookListResponse{}
+		if unmarshalError := json.Unmarshal(successResp.Data, &playbookList); unmarshalError != nil {
+			return nil, unmarshalError
+		}
+		playbooks = append(playbooks, playbookList.Results...)
+		if page >= playbookList.TotalPages || len(playbookList.Results) == 0 {
+			return playbooks, nil
+		}
+	}
+}
+
+// LoadPlaybookFiles reads every .yaml, .yml and .json file of dir as a PlaybookConfig.
+// The files use the field names of the ThreatMatrix REST API.
+func LoadPlaybookFiles(dir string) ([]PlaybookConfig, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	playbooks := []PlaybookConfig{}
+	fileByName := map[string]string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		extension := strings.ToLower(filepath.Ext(entry.Name()))
+		if extension != ".yaml" && extension != ".yml" && extension != ".json" {
+			continue
+		}
+		filePath := filepath.Join(dir, entry.Name())
+		fileBytes, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		if extension != ".json" {
+			// * going through JSON so that the json tags of PlaybookConfig are the only field names
+			var document interface{}
+			if err := yaml.Unmarshal(fileBytes, &document); err != nil {
+				return nil, fmt.Errorf("%s: %w", filePath, err)
+			}
+			if fileBytes, err = json.Marshal(document); err != nil {
+				return nil, fmt.Errorf("%s: %w", filePath, err)
+			}
+		}
+		playbook := PlaybookConfig{}
+		if err := json.Unmarshal(fileBytes, &playbook); err != nil {
+			return nil, fmt.Errorf("%s: %w", filePath, err)
+		}
+		if playbook.Name == "" {
+			return nil, fmt.Errorf("%s: playbook name cannot be empty", filePath)
+		}
+		if otherFile, ok := fileByName[playbook.Name]; ok {
+			return nil, fmt.Errorf("%s: playbook %s is already defined in %s", filePath, playbook.Name, otherFile)
+		}
+		fileByName[playbook.Name] = filePath
+		playbooks = append(playbooks, playbook)
+	}
+	return playbooks, nil
+}
+
+// playbookFileFields turns a playbook into the fields written in its file, leaving out the ones set by the server.
+func playbookFileFields(playbook *PlaybookConfig) (map[string]interface{}, error) {
+	playbookJson, err := json.Marshal(playbook)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(playbookJson, &fields); err != nil {
+		return nil, err
+	}
+	delete(fields, "id")
+	delete(fields, "owner")
+	return fields, nil
+}
+
+// ExportPlaybooks writes every playbook of your ThreatMatrix instance into dir, one file per playbook named after it,
+// in the format read by LoadPlaybookFiles. It returns the paths of the written files.
+func (playbookService *PlaybookService) ExportPlaybooks(ctx context.Context, dir string, format string) ([]string, error) {
+	if format != PlaybookFormatYAML && format != PlaybookFormatJSON {
+		return nil, fmt.Errorf("Unsupported playbook format %q", format)
+	}
+	playbooks, err := playbookService.ListAllPlaybooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	filePaths := []string{}
+	for index := range playbooks {
+		fields, err := playbookFileFields(&playbooks[index])
+		if err != nil {
+			return filePaths, err
+		}
+		var fileBytes []byte
+		if format == PlaybookFormatYAML {
+			fileBytes, err = yaml.Marshal(fields)
+		} else {
+			fileBytes, err = json.MarshalIndent(fields, "", "  ")
+		}
+		if err != nil {
+			return filePaths, err
+		}
+		fileName := strings.ReplaceAll(playbooks[index].Name, string(filepath.Separator), "_") + "." + format
+		filePath := filepath.Join(dir, fileName)
+		if err := os.WriteFile(filePath, fileBytes, 0644); err != nil {
+			return filePaths, err
+		}
+		filePaths = append(filePaths, filePath)
+	}
+	return filePaths, nil
+}
+
+// sameStrings compares two lists of names regardless of their order.
+func sameStrings(first []string, second []string) bool {
+	if len(first) != len(second) {
+		return false
+	}
+	sortedFirst := append([]string{}, first...)
+	sortedSecond := append([]string{}, second...)
+	sort.Strings(sortedFirst)
+	sort.Strings(sortedSecond)
+	return reflect.DeepEqual(sortedFirst, sortedSecond)
+}
+
+// sameRuntimeConfiguration compares two runtime configurations, treating nil and empty as equal.
+func sameRuntimeConfiguration(first map[string]interface{}, second map[string]interface{}) bool {
+	if len(first) == 0 && len(second) == 0 {
+		return true
+	}
+	firstJson, firstErr := json.Marshal(first)
+	secondJson, secondErr := json.Marshal(second)
+	if firstErr != nil || secondErr != nil {
+		return false
+	}
+	var firstValue, secondValue interface{}
+	json.Unmarshal(firstJson, &firstValue)
+	json.Unmarshal(secondJson, &secondValue)
+	return reflect.DeepEqual(firstValue, secondValue)
+}
+
+// diffPlaybooks returns the JSON names of the synced fields that differ between two playbooks.
+func diffPlaybooks(current *PlaybookConfig, desired *PlaybookConfig) []string {
+	fields := []string{}
+	if current.Description != desired.Description {
+		fields = append(fields, "description")
+	}
+	if !sameStrings(current.Type, desired.Type) {
+		fields = append(fields, "type")
+	}
+	if !sameStrings(current.Analyzers, desired.Analyzers) {
+		fields = append(fields, "analyzers")
+	}
+	if !sameStrings(current.Connectors, desired.Connectors) {
+		fields = append(fields, "connectors")
+	}
+	if !sameStrings(current.Pivots, desired.Pivots) {
+		fields = append(fields, "pivots")
+	}
+	if !sameRuntimeConfiguration(current.RuntimeConfiguration, desired.RuntimeConfiguration) {
+		fields = append(fields, "runtime_configuration")
+	}
+	if !strings.EqualFold(current.TLP, desired.TLP) {
+		fields = append(fields, "tlp")
+	}
+	if !sameStrings(current.Tags, desired.Tags) {
+		fields = append(fields, "tags")
+	}
+	if current.ScanMode != desired.ScanMode {
+		fields = append(fields, "scan_mode")
+	}
+	if current.Disabled != desired.Disabled {
+		fields = append(fields, "disabled")
+	}
+	if current.Starting != desired.Starting {
+		fields = append(fields, "starting")
+	}
+	return fields
+}
+
+// SyncPlaybooks reconciles the playbooks of your ThreatMatrix instance with the desired list.
+// Playbooks are matched by name: missing ones are created, ones whose description, type, analyzers, connectors,
+// pivots, runtime configuration, TLP, tags, scan mode, disabled or starting flags differ are updated and,
+// with PlaybookSyncOptions.Prune, the ones that are not desired are deleted.
+// Every desired playbook is validated, fetching the plugin configurations once, before anything is applied.
+// The plan is returned even when applying it fails midway, Applied tells whether it went through.
+func (playbookService *PlaybookService) SyncPlaybooks(ctx context.Context, desiredPlaybooks []PlaybookConfig, options *PlaybookSyncOptions) (*PlaybookSyncPlan, error) {
+	if options == nil {
+		options = &PlaybookSyncOptions{}
+	}
+	currentPlaybooks, err := playbookService.ListAllPlaybooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	currentByName := map[string]PlaybookConfig{}
+	for _, playbook := range currentPlaybooks {
+		currentByName[playbook.Name] = playbook
+	}
+	plan := &PlaybookSyncPlan{}
+	plugins := &playbookPlugins{}
+	desiredNames := map[string]bool{}
+	for _, desiredPlaybook := range desiredPlaybooks {
+		if desiredNames[desiredPlaybook.Name] {
+			return nil, fmt.Errorf("Playbook %s is desired more than once", desiredPlaybook.Name)
+		}
+		desiredNames[desiredPlaybook.Name] = true
+		if err := playbookService.validate(ctx, &desiredPlaybook, plugins); err != nil {
+			return nil, fmt.Errorf("playbook %s: %w", desiredPlaybook.Name, err)
+		}
+		currentPlaybook, ok := currentByName[desiredPlaybook.Name]
+		if !ok {
+			plan.Create = append(plan.Create, desiredPlaybook)
+			continue
+		}
+		if fields := diffPlaybooks(&currentPlaybook, &desiredPlaybook); len(fields) > 0 {
+			plan.Update = append(plan.Update, PlaybookChange{
+				Name:    desiredPlaybook.Name,
+				Fields:  fields,
+				Current: currentPlaybook,
+				Desired: desiredPlaybook,
+			})
+		}
+	}
+	if options.Prune {
+		for _, playbook := range currentPlaybooks {
+			if !desiredNames[playbook.Name] {
+				plan.Delete = append(plan.Delete, playbook)
+			}
+		}
+	}
+	if options.DryRun {
+		return plan, nil
+	}
+
+	for index := range plan.Create {
+		if _, err := playbookService.create(ctx, &plan.Create[index]); err != nil {
+			return plan, err
+		}
+	}
+	for index := range plan.Update {
+		change := &plan.Update[index]
+		if _, err := playbookService.update(ctx, change.Name, &change.Desired); err != nil {
+			return plan, err
+		}
+	}
+	for _, playbook := range plan.Delete {
+		if _, err := playbookService.Delete(ctx, playbook.Name); err != nil {
+			return plan, err
+		}
+	}
+	plan.Applied = true
+	return plan, nil
+}
+
+// Sync reconciles the playbooks of your ThreatMatrix instance with the YAML/JSON definitions found in dir.
+// See LoadPlaybookFiles for the file format and SyncPlaybooks for how the playbooks are reconciled.
+func (playbookService *PlaybookService) Sync(ctx context.Context, dir string, options *PlaybookSyncOptions) (*PlaybookSyncPlan, error) {
+	desiredPlaybooks, err := LoadPlaybookFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	return playbookService.SyncPlaybooks(ctx, desiredPlaybooks, options)
+}
@@ -0,0 +1,217 @@
+package gothreatmatrix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/khulnasoft/go-threatmatrix/constants"
+	"github.com/sirupsen/logrus"
+)
+
+// redactedSecret replaces secret values wherever they could be printed.
+const redactedSecret = "********"
+
+// SecretValue represents the value of a plugin secret, e.g an API key.
+// It is redacted when printed or marshalled so it never ends up in logs, use Reveal to read it.
+type SecretValue string
+
+// String lets you implement the fmt.Stringer interface, it always returns a redacted value.
+func (secret SecretValue) String() string {
+	return redactedSecret
+}
+
+// GoString lets you implement the fmt.GoStringer interface, it always returns a redacted value.
+func (secret SecretValue) GoString() string {
+	return redactedSecret
+}
+
+// MarshalJSON lets you implement the json.Marshaler interface, it always returns a redacted value.
+func (secret SecretValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redactedSecret)
+}
+
+// Reveal returns the actual value of the secret.
+func (secret SecretValue) Reveal() string {
+	return string(secret)
+}
+
+// getPluginConfig fetches the configuration of a single plugin into config.
+func (client *Client) getPluginConfig(ctx context.Context, route string, pluginName string, config interface{}) error {
+	requestUrl := client.options.Url + fmt.Sprintf(route, pluginName)
+	contentType := constants.ContentTypeJSON
+	method := http.MethodGet
+	request, err := client.buildRequest(ctx, method, contentType, nil, requestUrl)
+	if err != nil {
+		return err
+	}
+	successResp, err := client.newRequest(ctx, request)
+	if err != nil {
+		return err
+	}
+	if unmarshalError := json.Unmarshal(successResp.Data, config); unmarshalError != nil {
+		return unmarshalError
+	}
+	return nil
+}
+
+// patchPluginConfig updates the given fields of a plugin configuration and decodes the updated configuration into config.
+func (client *Client) patchPluginConfig(ctx context.Context, route string, pluginName string, fields map[string]interface{}, config interface{}) error {
+	requestUrl := client.options.Url + fmt.Sprintf(route, pluginName)
+	contentType := constants.ContentTypeJSON
+	method := http.MethodPatch
+	fieldsJson, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	request, err := client.buildRequest(ctx, method, contentType, bytes.NewBuffer(fieldsJson), requestUrl)
+	if err != nil {
+		return err
+	}
+	successResp, err := client.newRequest(ctx, request)
+	if err != nil {
+		return err
+	}
+	if unmarshalError := json.Unmarshal(successResp.Data, config); unmarshalError != nil {
+		return unmarshalError
+	}
+	return nil
+}
+
+// setPluginDisabled flips the Disabled field of a plugin, decodes the updated configuration into config and drops the ConfigCache.
+func (client *Client) setPluginDisabled(ctx context.Context, route string, pluginName string, disabled bool, config interface{}) error {
+	fields := map[string]interface{}{"disabled": disabled}
+	if err := client.patchPluginConfig(ctx, route, pluginName, fields, config); err != nil {
+		return err
+	}
+	client.ConfigCache.Invalidate()
+	return nil
+}
+
+// checkPluginAttributes makes sure every attribute is declared by the plugin, so typos are caught before anything is sent.
+func checkPluginAttributes(pluginName string, kind string, declared []string, attributes []string) error {
+	declaredSet := map[string]bool{}
+	for _, name := range declared {
+		declaredSet[name] = true
+	}
+	unknown := []string{}
+	for _, attribute := range attributes {
+		if !declaredSet[attribute] {
+			unknown = append(unknown, attribute)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("%s does not declare the %s: %s", pluginName, kind, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of a map of values in alphabetical order.
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// redactSecrets removes the secret values the server may echo back in an error message.
+func redactSecrets(err error, secrets []string) error {
+	threatMatrixError := &Error{}
+	if !errors.As(err, &threatMatrixError) {
+		return err
+	}
+	message := threatMatrixError.Message
+	for _, secret := range secrets {
+		if secret != "" {
+			message = strings.ReplaceAll(message, secret, redactedSecret)
+		}
+	}
+	return newError(threatMatrixError.StatusCode, message, threatMatrixError.Response)
+}
+
+// setPluginConfigValues sets organization-level values of a plugin, pluginField being the field naming the plugin e.g analyzer_config.
+// Only the attribute names are logged, the values of secrets are also redacted from the returned errors.
+//
+//	Endpoint: POST /api/plugin-config
+func (client *Client) setPluginConfigValues(ctx context.Context, pluginField string, pluginName string, values map[string]interface{}, secrets []string) error {
+	if len(values) == 0 {
+		return errors.New("At least one value is required")
+	}
+	attributes := sortedKeys(values)
+	pluginConfigs := make([]map[string]interface{}, 0, len(values))
+	for _, attribute := range attributes {
+		pluginConfigs = append(pluginConfigs, map[string]interface{}{
+			"attribute":        attribute,
+			"value":            values[attribute],
+			"for_organization": true,
+			pluginField:        pluginName,
+		})
+	}
+	client.Logger.Logger.WithFields(logrus.Fields{
+		"plugin":     pluginName,
+		"attributes": attributes,
+	}).Debug("Setting organization plugin config")
+
+	requestUrl := client.options.Url + constants.PLUGIN_CONFIG_URL
+	contentType := constants.ContentTypeJSON
+	method := http.MethodPost
+	pluginConfigsJson, err := json.Marshal(pluginConfigs)
+	if err != nil {
+		return err
+	}
+	request, err := client.buildRequest(ctx, method, contentType, bytes.NewBuffer(pluginConfigsJson), requestUrl)
+	if err != nil {
+		return err
+	}
+	if _, err := client.newRequest(ctx, request); err != nil {
+		return redactSecrets(err, secrets)
+	}
+	return nil
+}
+
+// paramNames returns the names of the params of a plugin configuration.
+func (baseConfiguration *BaseConfigurationType) paramNames() []string {
+	names := make([]string, 0, len(baseConfiguration.Params))
+	for name := range baseConfiguration.Params {
+		names = append(names, name)
+	}
+	return names
+}
+
+// secretNames returns the names of the secrets of a plugin configuration.
+func (baseConfiguration *BaseConfigurationType) secretNames() []string {
+	names := make([]string, 0, len(baseConfiguration.Secrets))
+	for name := range baseConfiguration.Secrets {
+		names = append(names, name)
+	}
+	return names
+}
+
+// revealSecrets turns the secrets into the values sent to the server and lists their plain values for redaction.
+func revealSecrets(secrets map[string]SecretValue) (map[string]interface{}, []string) {
+	values := map[string]interface{}{}
+	plainValues := []string{}
+	for name, secret := range secrets {
+		values[name] = secret.Reveal()
+		plainValues = append(plainValues, secret.Reveal())
+	}
+	return values, plainValues
+}
+
+// secretKeys returns the names of the secrets in alphabetical order.
+func secretKeys(secrets map[string]SecretValue) []string {
+	keys := make([]string, 0, len(secrets))
+	for key := range secrets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,245 @@
+package gothreatmatrix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultQuotaRefreshInterval is how often the QuotaGuard fetches your access details when QuotaGuardOptions.RefreshInterval is not set.
+const DefaultQuotaRefreshInterval = 5 * time.Minute
+
+// DefaultQuotaWarnRatio is the share of the monthly budget after which the QuotaGuard warns when QuotaGuardOptions.WarnRatio is not set.
+const DefaultQuotaWarnRatio = 0.9
+
+// ErrQuotaExceeded is matched by errors.Is when a submission is blocked by the QuotaGuard.
+var ErrQuotaExceeded = errors.New("Monthly submission budget exceeded")
+
+// QuotaExceededError represents a submission blocked by the QuotaGuard because it would go past the monthly budget.
+type QuotaExceededError struct {
+	MonthlyBudget    int
+	MonthSubmissions int
+	Requested        int
+}
+
+// Error lets you implement the error interface.
+func (quotaError *QuotaExceededError) Error() string {
+	return fmt.Sprintf("%s: %d of %d submissions used this month, %d more requested", ErrQuotaExceeded, quotaError.MonthSubmissions, quotaError.MonthlyBudget, quotaError.Requested)
+}
+
+// Is lets errors.Is match a QuotaExceededError with ErrQuotaExceeded.
+func (quotaError *QuotaExceededError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+// QuotaGuardOptions represents the fields needed to enable the QuotaGuard.
+type QuotaGuardOptions struct {
+	// MonthlyBudget is the number of submissions allowed per month, it must be greater than zero.
+	MonthlyBudget int
+	// WarnRatio is the share of MonthlyBudget after which a warning is logged, it defaults to 0.9.
+	WarnRatio float64
+	// RefreshInterval is how often the access details are fetched, it defaults to 5 minutes.
+	RefreshInterval time.Duration
+}
+
+// QuotaUsage represents the submissions of the current month as tracked by the QuotaGuard.
+type QuotaUsage struct {
+	MonthlyBudget    int       `json:"monthly_budget"`
+	MonthSubmissions int       `json:"month_submissions"`
+	Remaining        int       `json:"remaining"`
+	RefreshedAt      time.Time `json:"refreshed_at"`
+}
+
+// QuotaGuard tracks the submissions of the month against a budget and blocks the analyses going past it.
+// It is disabled until Enable is called and is shared by every service of a Client.
+// The submissions are fetched through UserService.Access every RefreshInterval and counted locally in between.
+type QuotaGuard struct {
+	client           *Client
+	mutex            sync.Mutex
+	enabled          bool
+	options          QuotaGuardOptions
+	monthSubmissions int
+	// pending counts the submissions made since the last refresh.
+	pending     int
+	refreshedAt time.Time
+	warned      bool
+}
+
+// Enable starts guarding the submissions with the given options.
+func (quotaGuard *QuotaGuard) Enable(options QuotaGuardOptions) error {
+	if options.MonthlyBudget <= 0 {
+		return errors.New("MonthlyBudget must be greater than zero")
+	}
+	if options.WarnRatio <= 0 || options.WarnRatio > 1 {
+		options.WarnRatio = DefaultQuotaWarnRatio
+	}
+	if options.RefreshInterval <= 0 {
+		options.RefreshInterval = DefaultQuotaRefreshInterval
+	}
+	quotaGuard.mutex.Lock()
+	defer quotaGuard.mutex.Unlock()
+	quotaGuard.enabled = true
+	quotaGuard.options = options
+	quotaGuard.refreshedAt = time.Time{}
+	quotaGuard.warned = false
+	return nil
+}
+
+// Disable stops guarding the submissions.
+func (quotaGuard *QuotaGuard) Disable() {
+	quotaGuard.mutex.Lock()
+	defer quotaGuard.mutex.Unlock()
+	quotaGuard.enabled = false
+}
+
+// Enabled reports whether the submissions are guarded.
+func (quotaGuard *QuotaGuard) Enabled() bool {
+	quotaGuard.mutex.Lock()
+	defer quotaGuard.mutex.Unlock()
+	return quotaGuard.enabled
+}
+
+// Refresh fetches the submissions of the month from ThreatMatrix right away.
+//
+//	Endpoint: GET /api/me/access
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/me/operation/me_access_retrieve
+func (quotaGuard *QuotaGuard) Refresh(ctx context.Context) error {
+	quotaGuard.mutex.Lock()
+	defer quotaGuard.mutex.Unlock()
+	return quotaGuard.refresh(ctx)
+}
+
+// refresh fetches the access details, the mutex must be held.
+func (quotaGuard *QuotaGuard) refresh(ctx context.Context) error {
+	user, err := quotaGuard.client.UserService.Access(ctx)
+	if err != nil {
+		return err
+	}
+	quotaGuard.monthSubmissions = user.Access.MonthSubmissions
+	quotaGuard.pending = 0
+	quotaGuard.refreshedAt = time.Now()
+	quotaGuard.warned = false
+	return nil
+}
+
+// stale reports whether the access details must be fetched again, the mutex must be held.
+// They are also stale once the month changed since the server resets the count.
+func (quotaGuard *QuotaGuard) stale() bool {
+	if quotaGuard.refreshedAt.IsZero() {
+		return true
+	}
+	now := time.Now()
+	if now.Year() != quotaGuard.refreshedAt.Year() || now.Month() != quotaGuard.refreshedAt.Month() {
+		return true
+	}
+	return now.Sub(quotaGuard.refreshedAt) >= quotaGuard.options.RefreshInterval
+}
+
+// usage returns the tracked usage, the mutex must be held.
+func (quotaGuard *QuotaGuard) usage() QuotaUsage {
+	used := quotaGuard.monthSubmissions + quotaGuard.pending
+	remaining := quotaGuard.options.MonthlyBudget - used
+	if remaining < 0 {
+		remaining = 0
+	}
+	return QuotaUsage{
+		MonthlyBudget:    quotaGuard.options.MonthlyBudget,
+		MonthSubmissions: used,
+		Remaining:        remaining,
+		RefreshedAt:      quotaGuard.refreshedAt,
+	}
+}
+
+// Usage returns the submissions of the month, refreshing them when they are older than RefreshInterval.
+func (quotaGuard *QuotaGuard) Usage(ctx context.Context) (*QuotaUsage, error) {
+	quotaGuard.mutex.Lock()
+	defer quotaGuard.mutex.Unlock()
+	if !quotaGuard.enabled {
+		return nil, errors.New("The quota guard is not enabled")
+	}
+	if quotaGuard.stale() {
+		if err := quotaGuard.refresh(ctx); err != nil {
+			return nil, err
+		}
+	}
+	usage := quotaGuard.usage()
+	return &usage, nil
+}
+
+// reserve counts the given number of submissions against the budget or returns a QuotaExceededError.
+// When the access details cannot be refreshed the last known ones are used, if any.
+func (quotaGuard *QuotaGuard) reserve(ctx context.Context, submissions int) error {
+	quotaGuard.mutex.Lock()
+	defer quotaGuard.mutex.Unlock()
+	if !quotaGuard.enabled {
+		return nil
+	}
+	if quotaGuard.stale() {
+		if err := quotaGuard.refresh(ctx); err != nil {
+			if quotaGuard.refreshedAt.IsZero() {
+				return err
+			}
+			quotaGuard.client.Logger.Logger.WithError(err).Warn("Could not refresh the submission quota, using the last known one")
+		}
+	}
+	used := quotaGuard.monthSubmissions + quotaGuard.pending
+	budget := quotaGuard.options.MonthlyBudget
+	if used+submissions > budget {
+		return &QuotaExceededError{
+			MonthlyBudget:    budget,
+			MonthSubmissions: used,
+			Requested:        submissions,
+		}
+	}
+	quotaGuard.pending += submissions
+	return nil
+}
+
+// warn logs once per refresh that the accepted submissions are close to the budget.
+func (quotaGuard *QuotaGuard) warn() {
+	quotaGuard.mutex.Lock()
+	defer quotaGuard.mutex.Unlock()
+	if !quotaGuard.enabled || quotaGuard.warned {
+		return
+	}
+	used := quotaGuard.monthSubmissions + quotaGuard.pending
+	budget := quotaGuard.options.MonthlyBudget
+	if float64(used) >= quotaGuard.options.WarnRatio*float64(budget) {
+		quotaGuard.warned = true
+		quotaGuard.client.Logger.Logger.WithFields(logrus.Fields{
+			"month_submissions": used,
+			"monthly_budget":    budget,
+		}).Warn("Monthly submission budget almost reached")
+	}
+}
+
+// release gives back submissions that were reserved but not accepted by ThreatMatrix.
+func (quotaGuard *QuotaGuard) release(submissions int) {
+	quotaGuard.mutex.Lock()
+	defer quotaGuard.mutex.Unlock()
+	quotaGuard.pending -= submissions
+	if quotaGuard.pending < 0 {
+		quotaGuard.pending = 0
+	}
+}
+
+// newSubmissionRequest makes a request creating the given number of jobs, once the QuotaGuard let them through.
+// The submissions are given back to the QuotaGuard when ThreatMatrix refused them, a warning is logged once accepted ones near the budget.
+func (client *Client) newSubmissionRequest(ctx context.Context, request *http.Request, submissions int) (*successResponse, error) {
+	if err := client.QuotaGuard.reserve(ctx, submissions); err != nil {
+		return nil, err
+	}
+	successResp, err := client.newRequest(ctx, request)
+	if err != nil {
+		client.QuotaGuard.release(submissions)
+		return nil, err
+	}
+	client.QuotaGuard.warn()
+	return successResp, nil
+}
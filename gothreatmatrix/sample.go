@@ -0,0 +1,232 @@
+package gothreatmatrix
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/khulnasoft/go-threatmatrix/constants"
+)
+
+// DefaultSamplePassword is the conventional password of zip archives holding malware samples.
+const DefaultSamplePassword = "infected"
+
+// ErrSampleIntegrity is returned when a downloaded sample does not match the job's MD5.
+var ErrSampleIntegrity = errors.New("sample integrity check failed")
+
+// SampleDownloadOptions represents the optional fields used when streaming a job's sample.
+type SampleDownloadOptions struct {
+	// Md5 is the expected hash of the sample, when empty the job's BaseJob.Md5 is used.
+	Md5 string
+	// SkipVerification disables the MD5 integrity check.
+	SkipVerification bool
+	// Password protects the archive written by DownloadSampleToZip, it defaults to DefaultSamplePassword.
+	Password string
+	// FileName is the name of the sample inside the archive written by DownloadSampleToZip,
+	// it defaults to the job's file name.
+	FileName string
+}
+
+// sampleJob fetches the job when the options need its MD5 or file name and fills them in.
+func (jobService *JobService) sampleJob(ctx context.Context, jobId uint64, options *SampleDownloadOptions, needsFileName bool) (*SampleDownloadOptions, error) {
+	resolved := SampleDownloadOptions{}
+	if options != nil {
+		resolved = *options
+	}
+	needsMd5 := !resolved.SkipVerification && resolved.Md5 == ""
+	if needsMd5 || (needsFileName && resolved.FileName == "") {
+		job, err := jobService.Get(ctx, jobId)
+		if err != nil {
+			return nil, err
+		}
+		if resolved.Md5 == "" {
+			resolved.Md5 = job.Md5
+		}
+		if resolved.FileName == "" {
+			resolved.FileName = job.FileName
+		}
+	}
+	if needsFileName {
+		resolved.FileName = filepath.Base(resolved.FileName)
+		if resolved.FileName == "." || resolved.FileName == string(filepath.Separator) {
+			resolved.FileName = fmt.Sprintf("job_%d_sample", jobId)
+		}
+	}
+	if resolved.Password == "" {
+		resolved.Password = DefaultSamplePassword
+	}
+	return &resolved, nil
+}
+
+// verifySample compares the computed hash against the expected MD5.
+func verifySample(options *SampleDownloadOptions, md5Hash hash.Hash) error {
+	if options.SkipVerification {
+		return nil
+	}
+	gotMd5 := hex.EncodeToString(md5Hash.Sum(nil))
+	if !strings.EqualFold(gotMd5, options.Md5) {
+		return fmt.Errorf("%w: expected md5 %s, got %s", ErrSampleIntegrity, options.Md5, gotMd5)
+	}
+	return nil
+}
+
+// streamSample requests the sample starting at offset.
+// partial reports whether the server honoured the Range request.
+func (jobService *JobService) streamSample(ctx context.Context, jobId uint64, offset int64) (response *http.Response, partial bool, err error) {
+	route := jobService.client.options.Url + constants.DOWNLOAD_SAMPLE_JOB_URL
+	requestUrl := fmt.Sprintf(route, jobId)
+	method := http.MethodGet
+	request, err := jobService.client.buildRequest(ctx, method, "", nil, requestUrl)
+	if err != nil {
+		return nil, false, err
+	}
+	if offset > 0 {
+		request.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	response, err = jobService.client.newStreamRequest(ctx, request)
+	if err != nil {
+		return nil, false, err
+	}
+	return response, response.StatusCode == http.StatusPartialContent, nil
+}
+
+// DownloadSampleToWriter streams the File sample of the given job into writer and verifies it against the job's MD5.
+// It returns the number of bytes written.
+//
+//	Endpoint: GET /api/jobs/{jobID}/download_sample
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/jobs/operation/jobs_download_sample_retrieve
+func (jobService *JobService) DownloadSampleToWriter(ctx context.Context, jobId uint64, writer io.Writer, options *SampleDownloadOptions) (int64, error) {
+	resolved, err := jobService.sampleJob(ctx, jobId, options, false)
+	if err != nil {
+		return 0, err
+	}
+	response, _, err := jobService.streamSample(ctx, jobId, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	md5Hash := md5.New()
+	written, err := io.Copy(io.MultiWriter(writer, md5Hash), response.Body)
+	if err != nil {
+		return written, err
+	}
+	return written, verifySample(resolved, md5Hash)
+}
+
+// DownloadSampleToFile streams the File sample of the given job into filePath and verifies it against the job's MD5.
+// If filePath already holds part of the sample the download is resumed through a Range request,
+// if it already holds all of it nothing more is downloaded.
+// The file is removed when the integrity check fails.
+// It returns the size of the file.
+//
+//	Endpoint: GET /api/jobs/{jobID}/download_sample
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/jobs/operation/jobs_download_sample_retrieve
+func (jobService *JobService) DownloadSampleToFile(ctx context.Context, jobId uint64, filePath string, options *SampleDownloadOptions) (int64, error) {
+	resolved, err := jobService.sampleJob(ctx, jobId, options, false)
+	if err != nil {
+		return 0, err
+	}
+	file, err := os.OpenFile(filePath, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	// * hashing what was already downloaded so the whole sample can be verified
+	md5Hash := md5.New()
+	offset, err := io.Copy(md5Hash, file)
+	if err != nil {
+		return 0, err
+	}
+	response, partial, err := jobService.streamSample(ctx, jobId, offset)
+	var threatMatrixError *Error
+	if offset > 0 && errors.As(err, &threatMatrixError) && threatMatrixError.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		// * nothing is left past the end of the file, it already holds the whole sample
+		if verifyError := verifySample(resolved, md5Hash); verifyError != nil {
+			file.Close()
+			os.Remove(filePath)
+			return 0, verifyError
+		}
+		return offset, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	if !partial {
+		// * the server sent the whole sample so we start over
+		if err := file.Truncate(0); err != nil {
+			return 0, err
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return 0, err
+		}
+		md5Hash.Reset()
+		offset = 0
+	}
+	written, err := io.Copy(io.MultiWriter(file, md5Hash), response.Body)
+	if err != nil {
+		return offset + written, err
+	}
+	if verifyError := verifySample(resolved, md5Hash); verifyError != nil {
+		file.Close()
+		os.Remove(filePath)
+		return 0, verifyError
+	}
+	return offset + written, nil
+}
+
+// DownloadSampleToZip streams the File sample of the given job straight into a password protected zip archive written to writer,
+// so the sample never touches the disk unwrapped. The archive uses ZipCrypto, the encryption every unzip tool understands.
+// The MD5 is verified on the plain sample before the archive is finalized, a failed check leaves it without its central directory.
+// It returns the size of the plain sample.
+//
+//	Endpoint: GET /api/jobs/{jobID}/download_sample
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/jobs/operation/jobs_download_sample_retrieve
+func (jobService *JobService) DownloadSampleToZip(ctx context.Context, jobId uint64, writer io.Writer, options *SampleDownloadOptions) (int64, error) {
+	resolved, err := jobService.sampleJob(ctx, jobId, options, true)
+	if err != nil {
+		return 0, err
+	}
+	response, _, err := jobService.streamSample(ctx, jobId, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	zipWriter := zip.NewWriter(writer)
+	header := &zip.FileHeader{
+		Name:     resolved.FileName,
+		Modified: time.Now(),
+	}
+	entryWriter, err := createEncryptedZipEntry(zipWriter, header, resolved.Password)
+	if err != nil {
+		return 0, err
+	}
+	md5Hash := md5.New()
+	written, err := io.Copy(io.MultiWriter(entryWriter, md5Hash), response.Body)
+	if err != nil {
+		return written, err
+	}
+	// * without its central directory a corrupted archive can't be opened
+	if err := verifySample(resolved, md5Hash); err != nil {
+		return written, err
+	}
+	if err := zipWriter.Close(); err != nil {
+		return written, err
+	}
+	return written, nil
+}
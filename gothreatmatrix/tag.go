@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/khulnasoft/go-threatmatrix/constants"
 )
@@ -31,6 +32,9 @@ type TagService struct {
 	client *Client
 }
 
+// ErrTagNotFound is returned when no tag carries the requested label.
+var ErrTagNotFound = errors.New("Tag not found")
+
 // checkTagID is used to check if a tag	ID is valid (id should be greater than zero).
 func checkTagID(id uint64) error {
 	if id > 0 {
@@ -95,13 +99,27 @@ func (tagService *TagService) Get(ctx context.Context, tagId uint64) (*Tag, erro
 }
 
 // Create lets you easily create a new tag by passing TagParams.
+// The color is normalized through NormalizeTagColor and the label must not be taken by another tag, whatever its case.
 //
 //	Endpoint: POST "/api/tags/"
 //
 // ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/tags/operation/tags_create
 func (tagService *TagService) Create(ctx context.Context, tagParams *TagParams) (*Tag, error) {
+	existingTags, err := tagService.listByLabel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	validTagParams, err := validateTagParams(0, tagParams, existingTags)
+	if err != nil {
+		return nil, err
+	}
+	return tagService.create(ctx, validTagParams)
+}
+
+// create sends tag params that were already validated.
+func (tagService *TagService) create(ctx context.Context, validTagParams *TagParams) (*Tag, error) {
 	requestUrl := tagService.client.options.Url + constants.BASE_TAG_URL
-	tagJson, err := json.Marshal(tagParams)
+	tagJson, err := json.Marshal(validTagParams)
 	if err != nil {
 		return nil, err
 	}
@@ -125,15 +143,32 @@ func (tagService *TagService) Create(ctx context.Context, tagParams *TagParams)
 }
 
 // Update lets you edit a tag throght its tag ID.
+// The color is normalized through NormalizeTagColor and the label must not be taken by another tag, whatever its case.
 //
 //	Endpoint: PUT "/api/tags/{id}"
 //
 // ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/tags/operation/tags_update
 func (tagService *TagService) Update(ctx context.Context, tagId uint64, tagParams *TagParams) (*Tag, error) {
+	if err := checkTagID(tagId); err != nil {
+		return nil, err
+	}
+	existingTags, err := tagService.listByLabel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	validTagParams, err := validateTagParams(tagId, tagParams, existingTags)
+	if err != nil {
+		return nil, err
+	}
+	return tagService.update(ctx, tagId, validTagParams)
+}
+
+// update sends tag params that were already validated.
+func (tagService *TagService) update(ctx context.Context, tagId uint64, validTagParams *TagParams) (*Tag, error) {
 	route := tagService.client.options.Url + constants.SPECIFIC_TAG_URL
 	requestUrl := fmt.Sprintf(route, tagId)
 	// Getting the relevant JSON data
-	tagJson, err := json.Marshal(tagParams)
+	tagJson, err := json.Marshal(validTagParams)
 	if err != nil {
 		return nil, err
 	}
@@ -183,3 +218,198 @@ func (tagService *TagService) Delete(ctx context.Context, tagId uint64) (bool, e
 	}
 	return false, nil
 }
+
+// validateTagParams normalizes the color and checks the label is not used by a tag other than tagId,
+// existingTags being the tags indexed by tagsByLabel.
+func validateTagParams(tagId uint64, tagParams *TagParams, existingTags map[string]Tag) (*TagParams, error) {
+	if strings.TrimSpace(tagParams.Label) == "" {
+		return nil, errors.New("Tag label cannot be empty")
+	}
+	color, err := NormalizeTagColor(tagParams.Color)
+	if err != nil {
+		return nil, err
+	}
+	if existingTag, ok := existingTags[strings.ToLower(tagParams.Label)]; ok && existingTag.ID != tagId {
+		return nil, fmt.Errorf("Tag label %q is already used by tag %d", tagParams.Label, existingTag.ID)
+	}
+	return &TagParams{
+		Label: tagParams.Label,
+		Color: color,
+	}, nil
+}
+
+// tagsByLabel indexes the tags by their lowercase label.
+func tagsByLabel(tags []Tag) map[string]Tag {
+	index := make(map[string]Tag, len(tags))
+	for _, tag := range tags {
+		index[strings.ToLower(tag.Label)] = tag
+	}
+	return index
+}
+
+// listByLabel fetches every tag indexed by its lowercase label.
+func (tagService *TagService) listByLabel(ctx context.Context) (map[string]Tag, error) {
+	tagList, err := tagService.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tagsByLabel(*tagList), nil
+}
+
+// GetByLabel fetches the tag carrying the given label, matched case-insensitively.
+// It returns ErrTagNotFound when there is no such tag.
+//
+//	Endpoint: GET "/api/tags"
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/tags/operation/tags_list
+func (tagService *TagService) GetByLabel(ctx context.Context, label string) (*Tag, error) {
+	existingTags, err := tagService.listByLabel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tag, ok := existingTags[strings.ToLower(label)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrTagNotFound, label)
+	}
+	return &tag, nil
+}
+
+// EnsureTag fetches the tag carrying the label of tagParams, matched case-insensitively, and creates it when there is none.
+// An existing tag is returned as it is, even when its color differs from tagParams.
+func (tagService *TagService) EnsureTag(ctx context.Context, tagParams *TagParams) (*Tag, error) {
+	existingTags, err := tagService.listByLabel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tag, ok := existingTags[strings.ToLower(tagParams.Label)]; ok {
+		return &tag, nil
+	}
+	validTagParams, err := validateTagParams(0, tagParams, existingTags)
+	if err != nil {
+		return nil, err
+	}
+	return tagService.create(ctx, validTagParams)
+}
+
+// DefaultTagColor is the color given to tags that are created on the fly.
+const DefaultTagColor = "#1c71d8"
+
+// resolveTagLabels maps every label to its tag, matching labels case-insensitively.
+// Missing labels are created with DefaultTagColor when autoCreate is set, otherwise they make it fail.
+func (tagService *TagService) resolveTagLabels(ctx context.Context, labels []string, autoCreate bool) ([]Tag, error) {
+	index, err := tagService.listByLabel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]Tag, 0, len(labels))
+	for _, label := range labels {
+		tag, ok := index[strings.ToLower(label)]
+		if !ok {
+			if !autoCreate {
+				return nil, fmt.Errorf("%w: %q", ErrTagNotFound, label)
+			}
+			validTagParams, err := validateTagParams(0, &TagParams{Label: label, Color: DefaultTagColor}, index)
+			if err != nil {
+				return nil, err
+			}
+			createdTag, err := tagService.create(ctx, validTagParams)
+			if err != nil {
+				return nil, err
+			}
+			tag = *createdTag
+			index[strings.ToLower(label)] = tag
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// TagUpdate represents a change to an existing tag planned by TagService.SyncTags.
+type TagUpdate struct {
+	Tag    Tag
+	Params TagParams
+}
+
+// TagSyncOptions represents the optional fields of TagService.SyncTags.
+type TagSyncOptions struct {
+	// DryRun only computes the plan without applying it.
+	DryRun bool
+	// Prune deletes the tags that are not in the desired list.
+	Prune bool
+}
+
+// TagSyncPlan represents the changes needed to reconcile the server's tags with a desired list.
+type TagSyncPlan struct {
+	Create  []TagParams
+	Update  []TagUpdate
+	Delete  []Tag
+	Applied bool
+}
+
+// SyncTags reconciles the tags of your ThreatMatrix instance with the desired list.
+// Tags are matched by label case-insensitively: missing tags are created, tags whose color or label case differ are updated
+// and, with TagSyncOptions.Prune, tags that are not desired are deleted.
+// The tags are listed once, the plan being checked against them before anything is applied.
+// The plan is returned even when applying it fails midway, Applied tells whether it went through.
+func (tagService *TagService) SyncTags(ctx context.Context, desiredTags []TagParams, options *TagSyncOptions) (*TagSyncPlan, error) {
+	if options == nil {
+		options = &TagSyncOptions{}
+	}
+	tagList, err := tagService.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existingTags := tagsByLabel(*tagList)
+	plan := &TagSyncPlan{}
+	desiredLabels := map[string]bool{}
+	for _, desiredTag := range desiredTags {
+		lowerLabel := strings.ToLower(desiredTag.Label)
+		if desiredLabels[lowerLabel] {
+			return nil, fmt.Errorf("Tag label %q is desired more than once", desiredTag.Label)
+		}
+		desiredLabels[lowerLabel] = true
+		existingTag, ok := existingTags[lowerLabel]
+		validTagParams, err := validateTagParams(existingTag.ID, &desiredTag, existingTags)
+		if err != nil {
+			return nil, err
+		}
+		params := *validTagParams
+		if !ok {
+			plan.Create = append(plan.Create, params)
+			continue
+		}
+		existingColor, err := NormalizeTagColor(existingTag.Color)
+		if existingTag.Label != params.Label || err != nil || existingColor != params.Color {
+			plan.Update = append(plan.Update, TagUpdate{Tag: existingTag, Params: params})
+		}
+	}
+	if options.Prune {
+		for _, tag := range *tagList {
+			if !desiredLabels[strings.ToLower(tag.Label)] {
+				plan.Delete = append(plan.Delete, tag)
+			}
+		}
+	}
+	if options.DryRun {
+		return plan, nil
+	}
+
+	// * deleting first so that pruned labels can't clash with the created ones
+	for _, tag := range plan.Delete {
+		if _, err := tagService.Delete(ctx, tag.ID); err != nil {
+			return plan, err
+		}
+	}
+	for index := range plan.Update {
+		if _, err := tagService.update(ctx, plan.Update[index].Tag.ID, &plan.Update[index].Params); err != nil {
+			return plan, err
+		}
+	}
+	for index := range plan.Create {
+		if _, err := tagService.create(ctx, &plan.Create[index]); err != nil {
+			return plan, err
+		}
+	}
+	plan.Applied = true
+	return plan, nil
+}
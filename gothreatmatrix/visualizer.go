@@ -0,0 +1,137 @@
+package gothreatmatrix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/khulnasoft/go-threatmatrix/constants"
+)
+
+// VisualizerConfig represents how a visualizer is configured in ThreatMatrix.
+//
+// ThreatMatrix docs: https://threatmatrix.readthedocs.io/en/latest/Usage.html#visualizers
+type VisualizerConfig struct {
+	BaseConfigurationType
+	Playbooks []string `json:"playbooks"`
+}
+
+// VisualizerService handles communication with visualizer related methods of the ThreatMatrix API.
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/visualizer
+type VisualizerService struct {
+	client *Client
+}
+
+// GetConfigs lists down every visualizer configuration in your ThreatMatrix instance.
+//
+//	Endpoint: GET /api/visualizer
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/visualizer/operation/visualizer_list
+func (visualizerService *VisualizerService) GetConfigs(ctx context.Context) (*[]VisualizerConfig, error) {
+	visualizerConfigurationResponse, err := visualizerService.fetchConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	visualizerNames := make([]string, 0)
+	// *getting all the visualizer key names!
+	for visualizerName := range visualizerConfigurationResponse {
+		visualizerNames = append(visualizerNames, visualizerName)
+	}
+	// * sorting them alphabetically
+	sort.Strings(visualizerNames)
+	visualizerConfigurationList := []VisualizerConfig{}
+	for _, visualizerName := range visualizerNames {
+		visualizerConfig := visualizerConfigurationResponse[visualizerName]
+		visualizerConfigurationList = append(visualizerConfigurationList, visualizerConfig)
+	}
+	return &visualizerConfigurationList, nil
+}
+
+// fetchConfigs downloads every visualizer configuration indexed by name.
+func (visualizerService *VisualizerService) fetchConfigs(ctx context.Context) (map[string]VisualizerConfig, error) {
+	requestUrl := visualizerService.client.options.Url + constants.VISUALIZER_CONFIG_URL
+	contentType := constants.ContentTypeJSON
+	method := http.MethodGet
+	request, err := visualizerService.client.buildRequest(ctx, method, contentType, nil, requestUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	successResp, err := visualizerService.client.newRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	visualizerConfigurationResponse := map[string]VisualizerConfig{}
+	if unmarshalError := json.Unmarshal(successResp.Data, &visualizerConfigurationResponse); unmarshalError != nil {
+		return nil, unmarshalError
+	}
+	return visualizerConfigurationResponse, nil
+}
+
+// HealthCheck checks if the specified visualizer is up and running
+//
+//	Endpoint: GET /api/visualizer/{NameOfVisualizer}/healthcheck
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/visualizer/operation/visualizer_healthcheck_retrieve
+func (visualizerService *VisualizerService) HealthCheck(ctx context.Context, visualizerName string) (bool, error) {
+	route := visualizerService.client.options.Url + constants.VISUALIZER_HEALTHCHECK_URL
+	requestUrl := fmt.Sprintf(route, visualizerName)
+	contentType := constants.ContentTypeJSON
+	method := http.MethodGet
+	request, err := visualizerService.client.buildRequest(ctx, method, contentType, nil, requestUrl)
+	if err != nil {
+		return false, err
+	}
+	status := StatusResponse{}
+	successResp, err := visualizerService.client.newRequest(ctx, request)
+	if err != nil {
+		return false, err
+	}
+	if unmarshalError := json.Unmarshal(successResp.Data, &status); unmarshalError != nil {
+		return false, unmarshalError
+	}
+	return status.Status, nil
+}
+
+// Get fetches the configuration of the specified visualizer.
+//
+//	Endpoint: GET /api/visualizer/{NameOfVisualizer}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/visualizer/operation/visualizer_retrieve
+func (visualizerService *VisualizerService) Get(ctx context.Context, visualizerName string) (*VisualizerConfig, error) {
+	visualizerConfig := VisualizerConfig{}
+	if err := visualizerService.client.getPluginConfig(ctx, constants.SPECIFIC_VISUALIZER_CONFIG_URL, visualizerName, &visualizerConfig); err != nil {
+		return nil, err
+	}
+	return &visualizerConfig, nil
+}
+
+// Enable lets you enable a visualizer.
+//
+//	Endpoint: PATCH /api/visualizer/{NameOfVisualizer}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/visualizer/operation/visualizer_partial_update
+func (visualizerService *VisualizerService) Enable(ctx context.Context, visualizerName string) (*VisualizerConfig, error) {
+	visualizerConfig := VisualizerConfig{}
+	if err := visualizerService.client.setPluginDisabled(ctx, constants.SPECIFIC_VISUALIZER_CONFIG_URL, visualizerName, false, &visualizerConfig); err != nil {
+		return nil, err
+	}
+	return &visualizerConfig, nil
+}
+
+// Disable lets you disable a visualizer.
+//
+//	Endpoint: PATCH /api/visualizer/{NameOfVisualizer}
+//
+// ThreatMatrix REST API docs: https://threatmatrix.readthedocs.io/en/latest/Redoc.html#tag/visualizer/operation/visualizer_partial_update
+func (visualizerService *VisualizerService) Disable(ctx context.Context, visualizerName string) (*VisualizerConfig, error) {
+	visualizerConfig := VisualizerConfig{}
+	if err := visualizerService.client.setPluginDisabled(ctx, constants.SPECIFIC_VISUALIZER_CONFIG_URL, visualizerName, true, &visualizerConfig); err != nil {
+		return nil, err
+	}
+	return &visualizerConfig, nil
+}
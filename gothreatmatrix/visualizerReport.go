@@ -0,0 +1,205 @@
+package gothreatmatrix
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// These represent the types of the elements a visualizer emits.
+const (
+	VisualizerElementBase           = "base"
+	VisualizerElementTitle          = "title"
+	VisualizerElementBool           = "bool"
+	VisualizerElementVerticalList   = "vertical_list"
+	VisualizerElementHorizontalList = "horizontal_list"
+	VisualizerElementTable          = "table"
+	VisualizerElementDownload       = "download"
+)
+
+// VisualizerReport represents the report of a visualizer on a job: a page made of levels stacked from top to bottom.
+type VisualizerReport struct {
+	Name                 string                 `json:"name"`
+	Status               string                 `json:"status"`
+	Levels               []VisualizerLevel      `json:"report"`
+	Errors               []string               `json:"errors"`
+	ProcessTime          float64                `json:"process_time"`
+	StartTime            time.Time              `json:"start_time"`
+	EndTime              time.Time              `json:"end_time"`
+	RuntimeConfiguration map[string]interface{} `json:"runtime_configuration"`
+	Config               string                 `json:"config"`
+	Type                 string                 `json:"type"`
+}
+
+// UnmarshalJSON lets you implement the json.Unmarshaler interface, a pending or failed visualizer sends an empty object as report so it leaves Levels empty.
+func (report *VisualizerReport) UnmarshalJSON(data []byte) error {
+	type visualizerReport VisualizerReport
+	wire := struct {
+		*visualizerReport
+		Levels json.RawMessage `json:"report"`
+	}{visualizerReport: (*visualizerReport)(report)}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	report.Levels = nil
+	if len(wire.Levels) == 0 || wire.Levels[0] != '[' {
+		return nil
+	}
+	return json.Unmarshal(wire.Levels, &report.Levels)
+}
+
+// VisualizerLevel represents a row of a visualizer page, Elements is usually a horizontal_list.
+type VisualizerLevel struct {
+	Position int               `json:"level_position"`
+	Size     string            `json:"level_size"`
+	Elements VisualizerElement `json:"elements"`
+}
+
+// VisualizerTableColumn represents a column of a table element.
+type VisualizerTableColumn struct {
+	Name           string `json:"name"`
+	MaxWidth       int    `json:"max_width,omitempty"`
+	Description    string `json:"description,omitempty"`
+	DisableFilters bool   `json:"disable_filters,omitempty"`
+	DisableSortBy  bool   `json:"disable_sort_by,omitempty"`
+}
+
+// UnmarshalJSON lets you implement the json.Unmarshaler interface, older visualizers name their columns with a plain string.
+func (column *VisualizerTableColumn) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*column = VisualizerTableColumn{Name: name}
+		return nil
+	}
+	type tableColumn VisualizerTableColumn
+	return json.Unmarshal(data, (*tableColumn)(column))
+}
+
+// VisualizerElement represents any element a visualizer emits, Type tells which fields are set:
+//   - base, bool and download show Value, decorated by Icon, Color and Link
+//   - title shows Title above TitleValue
+//   - vertical_list shows ListName above Values, horizontal_list shows Values side by side
+//   - table shows Data, one map of elements per row indexed by column name
+type VisualizerElement struct {
+	Type      string `json:"type"`
+	Size      string `json:"size,omitempty"`
+	Alignment string `json:"alignment,omitempty"`
+	Disable   bool   `json:"disable,omitempty"`
+
+	// * base, bool and download elements
+	Value       string `json:"-"`
+	Icon        string `json:"icon,omitempty"`
+	Color       string `json:"color,omitempty"`
+	Link        string `json:"link,omitempty"`
+	Description string `json:"description,omitempty"`
+	CopyText    string `json:"copy_text,omitempty"`
+	Bold        bool   `json:"bold,omitempty"`
+	Italic      bool   `json:"italic,omitempty"`
+	Mimetype    string `json:"mimetype,omitempty"`
+	Payload     string `json:"payload,omitempty"`
+
+	// * title elements
+	Title      *VisualizerElement `json:"title,omitempty"`
+	TitleValue *VisualizerElement `json:"-"`
+
+	// * list elements
+	ListName        *VisualizerElement  `json:"name,omitempty"`
+	Values          []VisualizerElement `json:"values,omitempty"`
+	Open            bool                `json:"open,omitempty"`
+	AddCountInTitle bool                `json:"add_count_in_title,omitempty"`
+	FillEmpty       bool                `json:"fill_empty,omitempty"`
+
+	// * table elements
+	Columns        []VisualizerTableColumn        `json:"columns,omitempty"`
+	Data           []map[string]VisualizerElement `json:"data,omitempty"`
+	PageSize       int                            `json:"page_size,omitempty"`
+	DisableFilters bool                           `json:"disable_filters,omitempty"`
+	DisableSortBy  bool                           `json:"disable_sort_by,omitempty"`
+	SortByID       string                         `json:"sort_by_id,omitempty"`
+	SortByDesc     bool                           `json:"sort_by_desc,omitempty"`
+}
+
+// visualizerElementJson is the wire form of a VisualizerElement, "value" is a string for most elements and an element for titles.
+type visualizerElementJson struct {
+	visualizerElementFields
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// visualizerElementFields drops the methods of VisualizerElement so decoding it does not recurse.
+type visualizerElementFields VisualizerElement
+
+// UnmarshalJSON lets you implement the json.Unmarshaler interface, it decodes "value" as a string or, for titles, as an element.
+func (element *VisualizerElement) UnmarshalJSON(data []byte) error {
+	wire := visualizerElementJson{}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	*element = VisualizerElement(wire.visualizerElementFields)
+	if len(wire.Value) == 0 || string(wire.Value) == "null" {
+		return nil
+	}
+	if wire.Value[0] == '{' {
+		titleValue := VisualizerElement{}
+		if err := json.Unmarshal(wire.Value, &titleValue); err != nil {
+			return err
+		}
+		element.TitleValue = &titleValue
+		return nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(wire.Value, &value); err != nil {
+		return err
+	}
+	if stringValue, ok := value.(string); ok {
+		element.Value = stringValue
+	} else {
+		// * numbers and booleans are shown as they were sent
+		element.Value = string(wire.Value)
+	}
+	return nil
+}
+
+// MarshalJSON lets you implement the json.Marshaler interface, it writes "value" back in the form it was read.
+func (element VisualizerElement) MarshalJSON() ([]byte, error) {
+	wire := visualizerElementJson{visualizerElementFields: visualizerElementFields(element)}
+	switch {
+	case element.TitleValue != nil:
+		titleValue, err := json.Marshal(element.TitleValue)
+		if err != nil {
+			return nil, err
+		}
+		wire.Value = titleValue
+	case element.Value != "":
+		value, err := json.Marshal(element.Value)
+		if err != nil {
+			return nil, err
+		}
+		wire.Value = value
+	}
+	return json.Marshal(wire)
+}
+
+// Children returns the elements nested in this one, in display order, so a renderer can walk the whole tree.
+func (element *VisualizerElement) Children() []*VisualizerElement {
+	children := []*VisualizerElement{}
+	if element.Title != nil {
+		children = append(children, element.Title)
+	}
+	if element.TitleValue != nil {
+		children = append(children, element.TitleValue)
+	}
+	if element.ListName != nil {
+		children = append(children, element.ListName)
+	}
+	for index := range element.Values {
+		children = append(children, &element.Values[index])
+	}
+	for _, row := range element.Data {
+		for _, column := range element.Columns {
+			if cell, ok := row[column.Name]; ok {
+				cell := cell
+				children = append(children, &cell)
+			}
+		}
+	}
+	return children
+}
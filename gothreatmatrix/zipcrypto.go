@@ -0,0 +1,100 @@
+package gothreatmatrix
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"crypto/rand"
+	"hash/crc32"
+	"io"
+	"time"
+)
+
+// zipCryptoKeys holds the state of the traditional PKWARE (ZipCrypto) stream cipher.
+//
+// PKWARE APPNOTE section 6.1: https://pkware.cachefly.net/webdocs/casestudies/APPNOTE.TXT
+type zipCryptoKeys [3]uint32
+
+func crc32Update(crc uint32, b byte) uint32 {
+	return (crc >> 8) ^ crc32.IEEETable[byte(crc)^b]
+}
+
+func newZipCryptoKeys(password string) *zipCryptoKeys {
+	keys := &zipCryptoKeys{0x12345678, 0x23456789, 0x34567890}
+	for index := 0; index < len(password); index++ {
+		keys.update(password[index])
+	}
+	return keys
+}
+
+func (keys *zipCryptoKeys) update(b byte) {
+	keys[0] = crc32Update(keys[0], b)
+	keys[1] = (keys[1]+(keys[0]&0xff))*134775813 + 1
+	keys[2] = crc32Update(keys[2], byte(keys[1]>>24))
+}
+
+func (keys *zipCryptoKeys) encrypt(b byte) byte {
+	temp := uint16(keys[2] | 2)
+	cipher := b ^ byte((temp*(temp^1))>>8)
+	keys.update(b)
+	return cipher
+}
+
+// zipCryptoWriter encrypts everything written to it before passing it on.
+// The encryption header is sent with the first write, since archive/zip
+// creates the compressor before writing the local file header.
+type zipCryptoWriter struct {
+	keys   *zipCryptoKeys
+	writer io.Writer
+	header []byte
+	buffer []byte
+}
+
+func (zipCrypto *zipCryptoWriter) Write(data []byte) (int, error) {
+	if zipCrypto.header != nil {
+		header := zipCrypto.header
+		zipCrypto.header = nil
+		if _, err := zipCrypto.Write(header); err != nil {
+			return 0, err
+		}
+	}
+	if cap(zipCrypto.buffer) < len(data) {
+		zipCrypto.buffer = make([]byte, len(data))
+	}
+	encrypted := zipCrypto.buffer[:len(data)]
+	for index, b := range data {
+		encrypted[index] = zipCrypto.keys.encrypt(b)
+	}
+	return zipCrypto.writer.Write(encrypted)
+}
+
+// createEncryptedZipEntry adds a deflated entry protected by ZipCrypto to zipWriter.
+// ZipCrypto is weak and only meant to keep malware samples from being opened or scanned by accident,
+// which is the convention of the "infected" password.
+func createEncryptedZipEntry(zipWriter *zip.Writer, header *zip.FileHeader, password string) (io.Writer, error) {
+	// * bit 0 marks the entry as encrypted, bit 3 makes the check byte come from the modification time
+	// * since the CRC is not known until the whole sample has been streamed.
+	header.Flags |= 0x1 | 0x8
+	header.Method = zip.Deflate
+	if !header.Modified.IsZero() {
+		// * archive/zip only derives the MS-DOS time inside CreateHeader, the check byte needs it earlier.
+		modified := header.Modified
+		header.ModifiedDate = uint16(modified.Day() + int(modified.Month())<<5 + (modified.Year()-1980)<<9)
+		header.ModifiedTime = uint16(modified.Second()/2 + modified.Minute()<<5 + modified.Hour()<<11)
+		header.Modified = time.Time{}
+	}
+	checkByte := byte(header.ModifiedTime >> 8)
+	zipWriter.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+		encryptionHeader := make([]byte, 12)
+		if _, err := rand.Read(encryptionHeader[:11]); err != nil {
+			return nil, err
+		}
+		encryptionHeader[11] = checkByte
+		cryptoWriter := &zipCryptoWriter{
+			keys:   newZipCryptoKeys(password),
+			writer: out,
+			header: encryptionHeader,
+		}
+		return flate.NewWriter(cryptoWriter, flate.DefaultCompression)
+	})
+	return zipWriter.CreateHeader(header)
+}
@@ -105,6 +105,62 @@ func TestCreateObservablePlaybookAnalysis(t *testing.T) {
 
 }
 
+func TestCreateMultipleObservablePlaybookAnalysis(t *testing.T) {
+	playbookAnalysisJsonString := `{"results":[{"job_id":3002,"analyzers_running":["Classic_DNS"],"connectors_running":[],"visualizers_running":[],"playbook_running":"FREE_TO_USE_ANALYZERS","status":"accepted"},{"job_id":3003,"analyzers_running":["Classic_DNS"],"connectors_running":[],"visualizers_running":[],"playbook_running":"FREE_TO_USE_ANALYZERS","status":"accepted"}],"count":2}`
+	playbookAnalysisResponse := gothreatmatrix.MultipleAnalysisResponse{}
+	if unmarshalError := json.Unmarshal([]byte(playbookAnalysisJsonString), &playbookAnalysisResponse); unmarshalError != nil {
+		t.Fatalf("Error: %s", unmarshalError)
+	}
+	basicAnalysisParams := gothreatmatrix.BasicAnalysisParams{
+		Tlp:                  gothreatmatrix.AMBER,
+		RuntimeConfiguration: map[string]interface{}{},
+		TagsLabels:           []string{"triage"},
+	}
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input: gothreatmatrix.MultipleObservablePlaybookAnalysisParams{
+			BasicAnalysisParams: basicAnalysisParams,
+			Observables:         [][]string{{"domain", "series9.io"}, {"ip", "8.8.8.8"}},
+			PlaybookRequested:   "FREE_TO_USE_ANALYZERS",
+		},
+		Data:       playbookAnalysisJsonString,
+		StatusCode: http.StatusOK,
+		Want:       &playbookAnalysisResponse,
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			params, ok := testCase.Input.(gothreatmatrix.MultipleObservablePlaybookAnalysisParams)
+			if ok {
+				// * the request body is recorded then checked from the test goroutine
+				data := struct {
+					Observables       [][]string `json:"observables"`
+					PlaybookRequested string     `json:"playbook_requested"`
+					Tlp               string     `json:"tlp"`
+				}{}
+				apiHandler.HandleFunc(constants.ANALYZE_OBSERVABLE_PLAYBOOK_URL, func(w http.ResponseWriter, r *http.Request) {
+					testMethod(t, r, "POST")
+					if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+						t.Errorf("Error: %s", err)
+					}
+					w.Write([]byte(testCase.Data))
+				})
+				gottenMultipleAnalysisResponse, err := client.CreateMultipleObservablePlaybookAnalysis(ctx, &params)
+				if err != nil {
+					testError(t, testCase, err)
+				} else {
+					testWantData(t, testCase.Want, gottenMultipleAnalysisResponse)
+				}
+				testWantData(t, params.Observables, data.Observables)
+				testWantData(t, params.PlaybookRequested, data.PlaybookRequested)
+				testWantData(t, "AMBER", data.Tlp)
+			}
+		})
+	}
+}
+
 func TestCreateMultipleObservableAnalysis(t *testing.T) {
 	multiAnalysisJsonString := `{"count":2,"results":[{"job_id":263,"status":"accepted","warnings":[],"analyzers_running":["Classic_DNS","CryptoScamDB_CheckAPI","Darksearch_Query","FireHol_IPList","FileScan_Search","GoogleWebRisk","GreyNoiseCommunity","InQuest_IOCdb","InQuest_REPdb","InQuest_DFI","MalwareBazaar_Google_Observable","Mnemonic_PassiveDNS","Phishstats","Pulsedive_Active_IOC","Robtex_IP_Query","Robtex_Reverse_PDNS_Query","Stratosphere_Blacklist","TalosReputation","ThreatFox","Threatminer_PDNS","Threatminer_Reports_Tagging","TorProject","URLhaus","UrlScan_Search","WhoIs_RipeDB_Search","YETI"],"connectors_running":["YETI"]},{"job_id":264,"status":"accepted","warnings":[],"analyzers_running":["Classic_DNS","CryptoScamDB_CheckAPI","Darksearch_Query","FireHol_IPList","FileScan_Search","GoogleWebRisk","GreyNoiseCommunity","InQuest_IOCdb","InQuest_REPdb","InQuest_DFI","MalwareBazaar_Google_Observable","Mnemonic_PassiveDNS","Phishstats","Pulsedive_Active_IOC","Robtex_IP_Query","Robtex_Reverse_PDNS_Query","Stratosphere_Blacklist","TalosReputation","ThreatFox","Threatminer_PDNS","Threatminer_Reports_Tagging","TorProject","URLhaus","UrlScan_Search","WhoIs_RipeDB_Search","YETI"],"connectors_running":["YETI"]}]}`
 	multiAnalysisResponse := gothreatmatrix.MultipleAnalysisResponse{}
@@ -194,9 +250,9 @@ func TestCreateFileAnalysis(t *testing.T) {
 			defer closeServer()
 			apiHandler.Handle(constants.ANALYZE_FILE_URL, serverHandler(t, testCase, "POST"))
 			ctx := context.Background()
-			fileAnalysisParams, ok := testCase.Input.(gothreatmatrix.FileAnalysisParams)
+			fileAnalysisParams, ok := testCase.Input.(*gothreatmatrix.FileAnalysisParams)
 			if ok {
-				gottenFileAnalysisResponse, err := client.CreateFileAnalysis(ctx, &fileAnalysisParams)
+				gottenFileAnalysisResponse, err := client.CreateFileAnalysis(ctx, fileAnalysisParams)
 				if err != nil {
 					testError(t, testCase, err)
 				} else {
@@ -260,7 +316,7 @@ func TestCreateFilesPlaybookAnalysis(t *testing.T) {
 		Input:      playbookFileParams,
 		Data:       playbookAnalysisJsonString,
 		StatusCode: http.StatusOK,
-		Want:       playbookAnalysisResponse,
+		Want:       &playbookAnalysisResponse,
 	}
 	for name, testCase := range testCases {
 		t.Run(name, func(t *testing.T) {
@@ -268,9 +324,9 @@ func TestCreateFilesPlaybookAnalysis(t *testing.T) {
 			defer closeServer()
 			apiHandler.Handle(constants.ANALYZE_FILE_PLAYBOOK_URL, serverHandler(t, testCase, "POST"))
 			ctx := context.Background()
-			playbookFilesAnalysisParams, ok := testCase.Input.(gothreatmatrix.FilePlaybookAnalysisParams)
+			playbookFilesAnalysisParams, ok := testCase.Input.(*gothreatmatrix.FilePlaybookAnalysisParams)
 			if ok {
-				gottenFilePlaybookAnalysisResponse, err := client.CreateFilePlaybookAnalysis(ctx, &playbookFilesAnalysisParams)
+				gottenFilePlaybookAnalysisResponse, err := client.CreateFilePlaybookAnalysis(ctx, playbookFilesAnalysisParams)
 				if err != nil {
 					testError(t, testCase, err)
 				} else {
@@ -317,7 +373,7 @@ func TestCreateMultipleFilesAnalysis(t *testing.T) {
 		Input:      multipleFileParams,
 		Data:       multiAnalysisJsonString,
 		StatusCode: http.StatusOK,
-		Want:       multiAnalysisResponse,
+		Want:       &multiAnalysisResponse,
 	}
 	for name, testCase := range testCases {
 		t.Run(name, func(t *testing.T) {
@@ -325,9 +381,9 @@ func TestCreateMultipleFilesAnalysis(t *testing.T) {
 			defer closeServer()
 			apiHandler.Handle(constants.ANALYZE_MULTIPLE_FILES_URL, serverHandler(t, testCase, "POST"))
 			ctx := context.Background()
-			multipleFilesAnalysisParams, ok := testCase.Input.(gothreatmatrix.MultipleFileAnalysisParams)
+			multipleFilesAnalysisParams, ok := testCase.Input.(*gothreatmatrix.MultipleFileAnalysisParams)
 			if ok {
-				gottenMultipleFilesAnalysisResponse, err := client.CreateMultipleFileAnalysis(ctx, &multipleFilesAnalysisParams)
+				gottenMultipleFilesAnalysisResponse, err := client.CreateMultipleFileAnalysis(ctx, multipleFilesAnalysisParams)
 				if err != nil {
 					testError(t, testCase, err)
 				} else {
@@ -338,3 +394,174 @@ func TestCreateMultipleFilesAnalysis(t *testing.T) {
 	}
 
 }
+
+func TestCreateMultipleFilePlaybookAnalysis(t *testing.T) {
+	multiAnalysisJsonString := `{"count":2,"results":[{"job_id":270,"status":"accepted","analyzers_running":["File_Info"],"connectors_running":[],"playbook_running":"Sample_Static_Analysis"},{"job_id":271,"status":"accepted","analyzers_running":["File_Info"],"connectors_running":[],"playbook_running":"Sample_Static_Analysis"}]}`
+	multiAnalysisResponse := gothreatmatrix.MultipleAnalysisResponse{}
+	if unmarshalError := json.Unmarshal([]byte(multiAnalysisJsonString), &multiAnalysisResponse); unmarshalError != nil {
+		t.Fatalf("Error: %s", unmarshalError)
+	}
+	fileDir := "./testFiles/"
+	file, _ := os.Open(path.Join(fileDir, "fileForAnalysis.txt"))
+	defer file.Close()
+	file2, _ := os.Open(path.Join(fileDir, "fileForAnalysis2.txt"))
+	defer file2.Close()
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input: gothreatmatrix.MultipleFilePlaybookAnalysisParams{
+			BasicAnalysisParams: gothreatmatrix.BasicAnalysisParams{
+				Tlp:                  gothreatmatrix.WHITE,
+				RuntimeConfiguration: map[string]interface{}{},
+			},
+			PlaybookRequested: "Sample_Static_Analysis",
+			Files:             []*os.File{file, file2},
+		},
+		Data:       multiAnalysisJsonString,
+		StatusCode: http.StatusOK,
+		Want:       &multiAnalysisResponse,
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			// * the form is recorded then checked from the test goroutine
+			fileNames := []string{}
+			var playbookRequested []string
+			apiHandler.HandleFunc(constants.ANALYZE_FILE_PLAYBOOK_URL, func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "POST")
+				if err := r.ParseMultipartForm(1 << 20); err != nil {
+					t.Errorf("Error: %s", err)
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				for _, fileHeader := range r.MultipartForm.File["files"] {
+					fileNames = append(fileNames, fileHeader.Filename)
+				}
+				playbookRequested = r.MultipartForm.Value["playbook_requested"]
+				w.Write([]byte(testCase.Data))
+			})
+			params, ok := testCase.Input.(gothreatmatrix.MultipleFilePlaybookAnalysisParams)
+			if ok {
+				gottenMultipleAnalysisResponse, err := client.CreateMultipleFilePlaybookAnalysis(ctx, &params)
+				if err != nil {
+					testError(t, testCase, err)
+				} else {
+					testWantData(t, testCase.Want, gottenMultipleAnalysisResponse)
+				}
+				testWantData(t, []string{"fileForAnalysis.txt", "fileForAnalysis2.txt"}, fileNames)
+				testWantData(t, []string{"Sample_Static_Analysis"}, playbookRequested)
+			}
+		})
+	}
+}
+
+func TestFileAnalysisForm(t *testing.T) {
+	analysisJsonString := `{"job_id":260,"status":"accepted","warnings":[],"analyzers_running":["File_Info"],"connectors_running":["YETI"]}`
+	multiAnalysisJsonString := `{"count":1,"results":[` + analysisJsonString + `]}`
+	fileDir := "./testFiles/"
+	file, _ := os.Open(path.Join(fileDir, "fileForAnalysis.txt"))
+	defer file.Close()
+	closedFile, _ := os.Open(path.Join(fileDir, "fileForAnalysis2.txt"))
+	closedFile.Close()
+	basicAnalysisParams := gothreatmatrix.BasicAnalysisParams{
+		User:                 1,
+		Tlp:                  gothreatmatrix.RED,
+		RuntimeConfiguration: map[string]interface{}{"analyzers": map[string]interface{}{"File_Info": map[string]interface{}{}}},
+		AnalyzersRequested:   []string{"File_Info", "Strings_Info"},
+		ConnectorsRequested:  []string{"YETI"},
+		TagsLabels:           []string{"malware"},
+	}
+	wantValues := map[string][]string{
+		"user":                  {"1"},
+		"tlp":                   {"RED"},
+		"runtime_configuration": {`{"analyzers":{"File_Info":{}}}`},
+		"analyzers_requested":   {"File_Info", "Strings_Info"},
+		"connectors_requested":  {"YETI"},
+		"tags_labels":           {"malware"},
+	}
+	wantPlaybookValues := map[string][]string{"playbook_requested": {"Sample_Static_Analysis"}}
+	for key, value := range wantValues {
+		wantPlaybookValues[key] = value
+	}
+	testCases := make(map[string]TestData)
+	testCases["file"] = TestData{
+		Input: &gothreatmatrix.FileAnalysisParams{BasicAnalysisParams: basicAnalysisParams, File: file},
+		Data:  analysisJsonString,
+		Want:  []interface{}{constants.ANALYZE_FILE_URL, "file", wantValues},
+	}
+	testCases["filePlaybook"] = TestData{
+		Input: &gothreatmatrix.FilePlaybookAnalysisParams{BasicAnalysisParams: basicAnalysisParams, PlaybookRequested: "Sample_Static_Analysis", File: file},
+		Data:  multiAnalysisJsonString,
+		Want:  []interface{}{constants.ANALYZE_FILE_PLAYBOOK_URL, "files", wantPlaybookValues},
+	}
+	testCases["multipleFiles"] = TestData{
+		Input: &gothreatmatrix.MultipleFileAnalysisParams{BasicAnalysisParams: basicAnalysisParams, Files: []*os.File{file}},
+		Data:  multiAnalysisJsonString,
+		Want:  []interface{}{constants.ANALYZE_MULTIPLE_FILES_URL, "files", wantValues},
+	}
+	testCases["closedFile"] = TestData{
+		Input: &gothreatmatrix.MultipleFileAnalysisParams{BasicAnalysisParams: basicAnalysisParams, Files: []*os.File{closedFile}},
+	}
+	testCases["noFile"] = TestData{
+		Input: &gothreatmatrix.MultipleFilePlaybookAnalysisParams{BasicAnalysisParams: basicAnalysisParams, PlaybookRequested: "Sample_Static_Analysis"},
+	}
+	testCases["nilFile"] = TestData{
+		Input: &gothreatmatrix.FileAnalysisParams{BasicAnalysisParams: basicAnalysisParams},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			// * the forms are recorded then checked from the test goroutine
+			requests := 0
+			var formValues map[string][]string
+			fileCount := 0
+			formHandler := func(fileField string) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					requests++
+					testMethod(t, r, "POST")
+					if err := r.ParseMultipartForm(1 << 20); err != nil {
+						t.Errorf("Error: %s", err)
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					formValues = r.MultipartForm.Value
+					fileCount = len(r.MultipartForm.File[fileField])
+					w.Write([]byte(testCase.Data))
+				}
+			}
+			wantError := testCase.Want == nil
+			if !wantError {
+				want := testCase.Want.([]interface{})
+				apiHandler.HandleFunc(want[0].(string), formHandler(want[1].(string)))
+			}
+			// * rewinding the shared file between the subtests
+			file.Seek(0, 0)
+			var err error
+			switch params := testCase.Input.(type) {
+			case *gothreatmatrix.FileAnalysisParams:
+				_, err = client.CreateFileAnalysis(ctx, params)
+			case *gothreatmatrix.FilePlaybookAnalysisParams:
+				_, err = client.CreateFilePlaybookAnalysis(ctx, params)
+			case *gothreatmatrix.MultipleFileAnalysisParams:
+				_, err = client.CreateMultipleFileAnalysis(ctx, params)
+			case *gothreatmatrix.MultipleFilePlaybookAnalysisParams:
+				_, err = client.CreateMultipleFilePlaybookAnalysis(ctx, params)
+			}
+			if wantError {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				testWantData(t, 0, requests)
+			} else if err != nil {
+				t.Fatalf("Error: %s", err)
+			} else {
+				testWantData(t, 1, requests)
+				testWantData(t, testCase.Want.([]interface{})[2].(map[string][]string), formValues)
+				testWantData(t, 1, fileCount)
+			}
+		})
+	}
+}
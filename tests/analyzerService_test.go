@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/khulnasoft/go-threatmatrix/constants"
 	"github.com/khulnasoft/go-threatmatrix/gothreatmatrix"
@@ -135,3 +136,321 @@ func TestAnalyzerServiceHealthCheck(t *testing.T) {
 		})
 	}
 }
+
+func TestAnalyzerServiceDisable(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input:      "Floss",
+		Data:       `{"name":"Floss","disabled":true}`,
+		StatusCode: http.StatusOK,
+		Want:       true,
+	}
+	testCases["analyzerDoesntExist"] = TestData{
+		Input:      "notAnAnalyzer",
+		Data:       `{"detail": "Not found."}`,
+		StatusCode: http.StatusNotFound,
+		Want: &gothreatmatrix.Error{
+			StatusCode: http.StatusNotFound,
+			Message:    `{"detail": "Not found."}`,
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			input := testCase.Input.(string)
+			body := map[string]interface{}{}
+			var decodeError error
+			apiHandler.HandleFunc(fmt.Sprintf(constants.SPECIFIC_ANALYZER_CONFIG_URL, input), func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "PATCH")
+				decodeError = json.NewDecoder(r.Body).Decode(&body)
+				w.WriteHeader(testCase.StatusCode)
+				w.Write([]byte(testCase.Data))
+			})
+			analyzerConfig, err := client.AnalyzerService.Disable(ctx, input)
+			if decodeError != nil {
+				t.Fatalf("Error: %s", decodeError)
+			}
+			testWantData(t, map[string]interface{}{"disabled": true}, body)
+			if err != nil {
+				testError(t, testCase, err)
+			} else {
+				testWantData(t, testCase.Want, analyzerConfig.Disabled)
+			}
+		})
+	}
+}
+
+func TestAnalyzerServiceSetSecrets(t *testing.T) {
+	unconfiguredJson := `{"name":"Shodan_Search","secrets":{"api_key_name":{"env_var_key":"SHODAN_KEY","required":true}},"params":{"shodan_analysis":{"value":"search","type":"str"}},"verification":{"configured":false,"missing_secrets":["api_key_name"]}}`
+	configuredJson := `{"name":"Shodan_Search","secrets":{"api_key_name":{"env_var_key":"SHODAN_KEY","required":true}},"params":{"shodan_analysis":{"value":"search","type":"str"}},"verification":{"configured":true,"missing_secrets":[]}}`
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input:      map[string]gothreatmatrix.SecretValue{"api_key_name": "s3cr3t-k3y"},
+		StatusCode: http.StatusCreated,
+		Want:       gothreatmatrix.VerificationType{Configured: true, MissingSecrets: []string{}},
+	}
+	testCases["serverEchoesSecret"] = TestData{
+		Input:      map[string]gothreatmatrix.SecretValue{"api_key_name": "s3cr3t-k3y"},
+		Data:       `{"errors":{"value":"s3cr3t-k3y is not valid"}}`,
+		StatusCode: http.StatusBadRequest,
+		Want: &gothreatmatrix.Error{
+			StatusCode: http.StatusBadRequest,
+			Message:    `{"errors":{"value":"******** is not valid"}}`,
+		},
+	}
+	testCases["undeclaredSecret"] = TestData{
+		Input: map[string]gothreatmatrix.SecretValue{"api_key": "s3cr3t-k3y"},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			configured := false
+			apiHandler.HandleFunc(fmt.Sprintf(constants.SPECIFIC_ANALYZER_CONFIG_URL, "Shodan_Search"), func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "GET")
+				if configured {
+					w.Write([]byte(configuredJson))
+					return
+				}
+				w.Write([]byte(unconfiguredJson))
+			})
+			posted := false
+			body := []map[string]interface{}{}
+			var decodeError error
+			apiHandler.HandleFunc(constants.PLUGIN_CONFIG_URL, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					w.Write([]byte(`[]`))
+					return
+				}
+				testMethod(t, r, "POST")
+				posted = true
+				decodeError = json.NewDecoder(r.Body).Decode(&body)
+				w.WriteHeader(testCase.StatusCode)
+				if testCase.StatusCode == http.StatusCreated {
+					configured = true
+					w.Write([]byte(`[]`))
+					return
+				}
+				w.Write([]byte(testCase.Data))
+			})
+			secrets := testCase.Input.(map[string]gothreatmatrix.SecretValue)
+			analyzerConfig, err := client.AnalyzerService.SetSecrets(ctx, "Shodan_Search", secrets)
+			if decodeError != nil {
+				t.Fatalf("Error: %s", decodeError)
+			}
+			if posted {
+				testWantData(t, []map[string]interface{}{{
+					"attribute":        "api_key_name",
+					"value":            "s3cr3t-k3y",
+					"for_organization": true,
+					"analyzer_config":  "Shodan_Search",
+				}}, body)
+			}
+			switch name {
+			case "simple":
+				if err != nil {
+					t.Fatalf("Error: %s", err)
+				}
+				testWantData(t, testCase.Want, analyzerConfig.Verification)
+			case "serverEchoesSecret":
+				testWantData(t, testCase.Want.(*gothreatmatrix.Error).Message, err.(*gothreatmatrix.Error).Message)
+			case "undeclaredSecret":
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				testWantData(t, false, posted)
+			}
+			// * secrets never show up when printed
+			testWantData(t, "map[api_key:********]", fmt.Sprint(map[string]gothreatmatrix.SecretValue{"api_key": "s3cr3t-k3y"}))
+		})
+	}
+}
+
+func TestAnalyzerServiceGet(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input:      "Floss",
+		Data:       `{"name":"Floss","python_module":"floss.Floss","disabled":false,"docker_based":true,"verification":{"configured":true,"missing_secrets":[]}}`,
+		StatusCode: http.StatusOK,
+		Want:       "floss.Floss",
+	}
+	testCases["analyzerDoesntExist"] = TestData{
+		Input:      "notAnAnalyzer",
+		Data:       `{"detail": "Not found."}`,
+		StatusCode: http.StatusNotFound,
+		Want: &gothreatmatrix.Error{
+			StatusCode: http.StatusNotFound,
+			Message:    `{"detail": "Not found."}`,
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			input := testCase.Input.(string)
+			apiHandler.Handle(fmt.Sprintf(constants.SPECIFIC_ANALYZER_CONFIG_URL, input), serverHandler(t, testCase, "GET"))
+			analyzerConfig, err := client.AnalyzerService.Get(ctx, input)
+			if err != nil {
+				testError(t, testCase, err)
+			} else {
+				testWantData(t, testCase.Want, analyzerConfig.PythonModule)
+			}
+		})
+	}
+}
+
+func TestAnalyzerServiceGetConfigsByName(t *testing.T) {
+	analyzerConfigJsonString := `{"Floss":{"name":"Floss","disabled":false},"Yara":{"name":"Yara","disabled":false,"params":{"rules":{"value":["community"],"type":"list"}},"supported_filetypes":["application/x-dosexec"]}}`
+	testCases := make(map[string]TestData)
+	testCases["cached"] = TestData{
+		Input: time.Duration(0),
+		Want:  1,
+	}
+	testCases["expired"] = TestData{
+		Input: time.Nanosecond,
+		Want:  2,
+	}
+	testCases["invalidatedByDisable"] = TestData{
+		Input: time.Duration(0),
+		Want:  2,
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			client.ConfigCache.TTL = testCase.Input.(time.Duration)
+			downloads := 0
+			apiHandler.HandleFunc(constants.ANALYZER_CONFIG_URL, func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "GET")
+				downloads++
+				w.Write([]byte(analyzerConfigJsonString))
+			})
+			apiHandler.HandleFunc(fmt.Sprintf(constants.SPECIFIC_ANALYZER_CONFIG_URL, "Floss"), func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"name":"Floss","disabled":true}`))
+			})
+			for i := 0; i < 2; i++ {
+				if name == "expired" {
+					time.Sleep(time.Millisecond)
+				}
+				analyzerConfigs, err := client.AnalyzerService.GetConfigsByName(ctx)
+				if err != nil {
+					t.Fatalf("Error: %s", err)
+				}
+				yara := analyzerConfigs["Yara"]
+				testWantData(t, "Yara", yara.Name)
+				testWantData(t, []interface{}{"community"}, yara.Params["rules"].Value)
+				testWantData(t, []string{"application/x-dosexec"}, yara.SupportedFiletypes)
+				// * changing the returned map and configurations leaves the cache untouched
+				yara.Params["rules"].Value.([]interface{})[0] = "custom"
+				yara.Params["timeout"] = gothreatmatrix.Parameter{Value: 60}
+				yara.SupportedFiletypes[0] = "text/plain"
+				delete(analyzerConfigs, "Yara")
+				if name == "invalidatedByDisable" && i == 0 {
+					if _, err := client.AnalyzerService.Disable(ctx, "Floss"); err != nil {
+						t.Fatalf("Error: %s", err)
+					}
+				}
+			}
+			testWantData(t, testCase.Want, downloads)
+		})
+	}
+}
+
+func TestAnalyzerServicePull(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input:      "Yara_Scan_Community",
+		Data:       `{"status": true}`,
+		StatusCode: http.StatusOK,
+		Want:       true,
+	}
+	testCases["notPullable"] = TestData{
+		Input:      "Classic_DNS",
+		Data:       `{"errors": {"detail": "No update implemented"}}`,
+		StatusCode: http.StatusBadRequest,
+		Want: &gothreatmatrix.Error{
+			StatusCode: http.StatusBadRequest,
+			Message:    `{"errors": {"detail": "No update implemented"}}`,
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			input := testCase.Input.(string)
+			apiHandler.Handle(fmt.Sprintf(constants.ANALYZER_PULL_URL, input), serverHandler(t, testCase, "POST"))
+			status, err := client.AnalyzerService.Pull(ctx, input)
+			if err != nil {
+				testError(t, testCase, err)
+			} else {
+				testWantData(t, testCase.Want, status)
+			}
+		})
+	}
+}
+
+func TestAnalyzerServicePullAll(t *testing.T) {
+	analyzerConfigJsonString := `{
+		"Yara_Scan_Community": {"name":"Yara_Scan_Community","python_module":"yara_scan.YaraScan","disabled":false},
+		"Floss": {"name":"Floss","python_module":"floss.Floss","disabled":false,"docker_based":true},
+		"APKiD": {"name":"APKiD","python_module":"apkid.APKiD","disabled":true,"docker_based":true},
+		"Classic_DNS": {"name":"Classic_DNS","python_module":"dns.dns_resolvers.classic_dns_resolver.ClassicDNSResolver","disabled":false}
+	}`
+	testCases := make(map[string]TestData)
+	testCases["default"] = TestData{
+		Input: &gothreatmatrix.PullParams{Concurrency: 2},
+		Want: []gothreatmatrix.PullResult{
+			{Name: "Classic_DNS", Unsupported: true, Error: "Status Code: 400 \n Error: {\"errors\": {\"detail\": \"No update implemented\"}}"},
+			{Name: "Floss", Error: "Status Code: 500 \n Error: {\"detail\": \"registry unreachable\"}"},
+			{Name: "Yara_Scan_Community", Success: true},
+		},
+	}
+	testCases["includeDisabled"] = TestData{
+		Input: &gothreatmatrix.PullParams{IncludeDisabled: true, Filter: func(analyzerConfig *gothreatmatrix.AnalyzerConfig) bool {
+			return analyzerConfig.DockerBased
+		}},
+		Want: []gothreatmatrix.PullResult{
+			{Name: "APKiD", Success: true},
+			{Name: "Floss", Error: "Status Code: 500 \n Error: {\"detail\": \"registry unreachable\"}"},
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			apiHandler.HandleFunc(constants.ANALYZER_CONFIG_URL, func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(analyzerConfigJsonString))
+			})
+			apiHandler.HandleFunc("/api/analyzer/", func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "POST")
+				switch r.URL.Path {
+				case fmt.Sprintf(constants.ANALYZER_PULL_URL, "Floss"):
+					w.WriteHeader(http.StatusInternalServerError)
+					w.Write([]byte(`{"detail": "registry unreachable"}`))
+					return
+				case fmt.Sprintf(constants.ANALYZER_PULL_URL, "Classic_DNS"):
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(`{"errors": {"detail": "No update implemented"}}`))
+					return
+				}
+				w.Write([]byte(`{"status": true}`))
+			})
+			results, err := client.AnalyzerService.PullAll(ctx, testCase.Input.(*gothreatmatrix.PullParams))
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			// * durations depend on the machine running the tests
+			for index := range results {
+				results[index].DurationMs = 0
+			}
+			testWantData(t, testCase.Want, results)
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/khulnasoft/go-threatmatrix/gothreatmatrix"
+)
+
+const profilesYaml = `default: prod
+profiles:
+  prod:
+    url: https://threatmatrix.example.com
+    token: env:PROD_THREATMATRIX_TOKEN
+    timeout: 30
+  lab:
+    url: https://lab.threatmatrix.example.com
+    token: file:%s
+  staging:
+    url: https://staging.threatmatrix.example.com
+    token: plain-staging-token
+`
+
+// clearThreatMatrixEnv unsets every variable read by LoadClientOptions and hides the default profiles file.
+func clearThreatMatrixEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{
+		gothreatmatrix.EnvUrl,
+		gothreatmatrix.EnvToken,
+		gothreatmatrix.EnvTokenFile,
+		gothreatmatrix.EnvCertificate,
+		gothreatmatrix.EnvTimeout,
+		gothreatmatrix.EnvProfile,
+		gothreatmatrix.EnvConfig,
+	} {
+		t.Setenv(name, "")
+	}
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	t.Setenv("HOME", configDir)
+}
+
+func TestLoadClientOptions(t *testing.T) {
+	dir := t.TempDir()
+	labTokenFile := filepath.Join(dir, "lab-token")
+	if err := os.WriteFile(labTokenFile, []byte("lab-token\n"), 0600); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	profilesFile := filepath.Join(dir, "profiles.yaml")
+	if err := os.WriteFile(profilesFile, []byte(fmt.Sprintf(profilesYaml, labTokenFile)), 0600); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+
+	testCases := make(map[string]TestData)
+	testCases["envOnly"] = TestData{
+		Input: map[string]string{
+			gothreatmatrix.EnvUrl:     "https://env.threatmatrix.example.com",
+			gothreatmatrix.EnvToken:   "env-token",
+			gothreatmatrix.EnvTimeout: "5",
+		},
+		Want: []interface{}{"https://env.threatmatrix.example.com", "env-token", uint64(5)},
+	}
+	testCases["defaultProfile"] = TestData{
+		Input: map[string]string{
+			gothreatmatrix.EnvConfig:  profilesFile,
+			"PROD_THREATMATRIX_TOKEN": "prod-token",
+		},
+		Want: []interface{}{"https://threatmatrix.example.com", "prod-token", uint64(30)},
+	}
+	testCases["fileReference"] = TestData{
+		Input: map[string]string{
+			gothreatmatrix.EnvConfig:  profilesFile,
+			gothreatmatrix.EnvProfile: "lab",
+		},
+		Want: []interface{}{"https://lab.threatmatrix.example.com", "lab-token", uint64(0)},
+	}
+	testCases["envOverFile"] = TestData{
+		Input: map[string]string{
+			gothreatmatrix.EnvConfig:  profilesFile,
+			gothreatmatrix.EnvProfile: "staging",
+			gothreatmatrix.EnvUrl:     "https://proxy.example.com",
+			gothreatmatrix.EnvTimeout: "60",
+		},
+		Want: []interface{}{"https://proxy.example.com", "plain-staging-token", uint64(60)},
+	}
+	testCases["unknownProfile"] = TestData{
+		Input: map[string]string{
+			gothreatmatrix.EnvConfig:  profilesFile,
+			gothreatmatrix.EnvProfile: "qa",
+		},
+	}
+	testCases["unsetReference"] = TestData{
+		Input: map[string]string{
+			gothreatmatrix.EnvConfig: profilesFile,
+		},
+	}
+	testCases["noToken"] = TestData{
+		Input: map[string]string{
+			gothreatmatrix.EnvUrl: "https://env.threatmatrix.example.com",
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			clearThreatMatrixEnv(t)
+			t.Setenv("PROD_THREATMATRIX_TOKEN", "")
+			for variable, value := range testCase.Input.(map[string]string) {
+				t.Setenv(variable, value)
+			}
+			options, err := gothreatmatrix.LoadClientOptions("", "")
+			if testCase.Want == nil {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			token := options.Token
+			if options.CredentialProvider != nil {
+				if token, err = options.CredentialProvider.Token(context.Background()); err != nil {
+					t.Fatalf("Error: %s", err)
+				}
+			}
+			testWantData(t, testCase.Want, []interface{}{options.Url, token, options.Timeout})
+		})
+	}
+}
+
+func TestNewClientFromProfile(t *testing.T) {
+	clearThreatMatrixEnv(t)
+	dir := t.TempDir()
+	profilesFile := filepath.Join(dir, "profiles.json")
+	profilesJson := `{"default":"lab","profiles":{"lab":{"url":"https://lab.threatmatrix.example.com","token":"lab-token"}}}`
+	if err := os.WriteFile(profilesFile, []byte(profilesJson), 0600); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	loggerParams := &gothreatmatrix.LoggerParams{}
+	if _, err := gothreatmatrix.NewClientFromProfile(profilesFile, "", nil, loggerParams); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if _, err := gothreatmatrix.NewClientFromProfile(filepath.Join(dir, "missing.json"), "", nil, loggerParams); err == nil {
+		t.Fatalf("expected an error")
+	}
+	// * without any profiles file the environment alone is enough
+	t.Setenv(gothreatmatrix.EnvUrl, "https://env.threatmatrix.example.com")
+	t.Setenv(gothreatmatrix.EnvToken, "env-token")
+	if _, err := gothreatmatrix.NewClientFromEnv(nil, loggerParams); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+}
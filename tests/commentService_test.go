@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/khulnasoft/go-threatmatrix/constants"
+	"github.com/khulnasoft/go-threatmatrix/gothreatmatrix"
+)
+
+func TestCommentServiceList(t *testing.T) {
+	commentListJson := `[{"id":1,"content":"looks like a false positive","user":{"username":"hussain"},"created_at":"2022-07-15T20:54:48.734361Z","updated_at":"2022-07-15T20:54:48.734361Z"},{"id":2,"content":"escalated","user":{"username":"analyst"},"created_at":"2022-07-16T10:00:00Z","updated_at":"2022-07-16T10:05:00Z"}]`
+	commentList := []gothreatmatrix.Comment{}
+	if unmarshalError := json.Unmarshal([]byte(commentListJson), &commentList); unmarshalError != nil {
+		t.Fatalf("Error: %s", unmarshalError)
+	}
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input:      72,
+		Data:       commentListJson,
+		StatusCode: http.StatusOK,
+		Want:       commentList,
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			id, ok := testCase.Input.(int)
+			if ok {
+				jobId := uint64(id)
+				queriedJobId := ""
+				apiHandler.HandleFunc(constants.BASE_COMMENT_URL, func(w http.ResponseWriter, r *http.Request) {
+					testMethod(t, r, "GET")
+					queriedJobId = r.URL.Query().Get("job_id")
+					w.Write([]byte(testCase.Data))
+				})
+				gottenCommentList, err := client.CommentService.List(ctx, jobId)
+				if err != nil {
+					testError(t, testCase, err)
+				} else {
+					testWantData(t, testCase.Want, *gottenCommentList)
+				}
+				testWantData(t, fmt.Sprint(jobId), queriedJobId)
+			}
+		})
+	}
+}
+
+func TestCommentServiceCreate(t *testing.T) {
+	commentJson := `{"id":3,"content":"triaged by automation","user":{"username":"bot"},"created_at":"2022-07-15T20:54:48Z","updated_at":"2022-07-15T20:54:48Z"}`
+	comment := gothreatmatrix.Comment{}
+	if unmarshalError := json.Unmarshal([]byte(commentJson), &comment); unmarshalError != nil {
+		t.Fatalf("Error: %s", unmarshalError)
+	}
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input: gothreatmatrix.CommentParams{
+			JobID:   72,
+			Content: "triaged by automation",
+		},
+		Data:       commentJson,
+		StatusCode: http.StatusCreated,
+		Want:       &comment,
+	}
+	testCases["jobNotFound"] = TestData{
+		Input: gothreatmatrix.CommentParams{
+			JobID:   9000,
+			Content: "triaged by automation",
+		},
+		Data:       `{"job_id":["Invalid pk \"9000\" - object does not exist."]}`,
+		StatusCode: http.StatusBadRequest,
+		Want: &gothreatmatrix.Error{
+			StatusCode: http.StatusBadRequest,
+			Message:    `{"job_id":["Invalid pk \"9000\" - object does not exist."]}`,
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			apiHandler.Handle(constants.BASE_COMMENT_URL, serverHandler(t, testCase, "POST"))
+			commentParams, ok := testCase.Input.(gothreatmatrix.CommentParams)
+			if ok {
+				gottenComment, err := client.CommentService.Create(ctx, &commentParams)
+				if err != nil {
+					testError(t, testCase, err)
+				} else {
+					testWantData(t, testCase.Want, gottenComment)
+				}
+			}
+		})
+	}
+}
+
+func TestCommentServiceGet(t *testing.T) {
+	commentJson := `{"id":1,"content":"looks like a false positive","user":{"username":"hussain"},"created_at":"2022-07-15T20:54:48.734361Z","updated_at":"2022-07-15T20:54:48.734361Z"}`
+	comment := gothreatmatrix.Comment{}
+	if unmarshalError := json.Unmarshal([]byte(commentJson), &comment); unmarshalError != nil {
+		t.Fatalf("Error: %s", unmarshalError)
+	}
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input:      1,
+		Data:       commentJson,
+		StatusCode: http.StatusOK,
+		Want:       &comment,
+	}
+	testCases["cantFind"] = TestData{
+		Input:      9000,
+		Data:       `{"detail":"Not found."}`,
+		StatusCode: http.StatusNotFound,
+		Want: &gothreatmatrix.Error{
+			StatusCode: http.StatusNotFound,
+			Message:    `{"detail":"Not found."}`,
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			id, ok := testCase.Input.(int)
+			if ok {
+				commentId := uint64(id)
+				testUrl := fmt.Sprintf(constants.SPECIFIC_COMMENT_URL, commentId)
+				apiHandler.Handle(testUrl, serverHandler(t, testCase, "GET"))
+				gottenComment, err := client.CommentService.Get(ctx, commentId)
+				if err != nil {
+					testError(t, testCase, err)
+				} else {
+					testWantData(t, testCase.Want, gottenComment)
+				}
+			}
+		})
+	}
+}
+
+func TestCommentServiceDelete(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input:      1,
+		Data:       "",
+		StatusCode: http.StatusNoContent,
+		Want:       true,
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			id, ok := testCase.Input.(int)
+			if ok {
+				commentId := uint64(id)
+				testUrl := fmt.Sprintf(constants.SPECIFIC_COMMENT_URL, commentId)
+				apiHandler.Handle(testUrl, serverHandler(t, testCase, "DELETE"))
+				isDeleted, err := client.CommentService.Delete(ctx, commentId)
+				if err != nil {
+					testError(t, testCase, err)
+				} else {
+					testWantData(t, testCase.Want, isDeleted)
+				}
+			}
+		})
+	}
+}
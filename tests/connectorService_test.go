@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"sort"
 	"testing"
@@ -93,3 +94,107 @@ func TestConnectorServiceHealthCheck(t *testing.T) {
 		})
 	}
 }
+
+func TestConnectorServiceSetParams(t *testing.T) {
+	connectorJson := `{"name":"MISP","disabled":false,"secrets":{},"params":{"ssl_check":{"value":true,"type":"bool"},"debug":{"value":false,"type":"bool"}},"verification":{"configured":true,"missing_secrets":[]}}`
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input: map[string]interface{}{"ssl_check": false, "debug": true},
+		Data:  `[]`,
+		Want: []string{
+			`POST /api/plugin-config [{"attribute":"debug","connector_config":"MISP","for_organization":true,"value":true},{"attribute":"ssl_check","connector_config":"MISP","for_organization":true,"value":false}]`,
+		},
+	}
+	// * the organization already overrides ssl_check so only debug is created
+	testCases["overridden"] = TestData{
+		Input: map[string]interface{}{"ssl_check": false, "debug": true},
+		Data:  `[{"id":11,"attribute":"ssl_check","value":true,"type":"bool","for_organization":true,"organization":"StrawHats","connector_config":"MISP"},{"id":12,"attribute":"debug","value":false,"type":"bool","for_organization":false,"connector_config":"MISP"}]`,
+		Want: []string{
+			`PATCH /api/plugin-config/11 {"value":false}`,
+			`POST /api/plugin-config [{"attribute":"debug","connector_config":"MISP","for_organization":true,"value":true}]`,
+		},
+	}
+	testCases["undeclaredParam"] = TestData{
+		Input: map[string]interface{}{"verify": false},
+		Data:  `[]`,
+		Want:  []string{},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			apiHandler.HandleFunc(fmt.Sprintf(constants.SPECIFIC_CONNECTOR_CONFIG_URL, "MISP"), func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "GET")
+				w.Write([]byte(connectorJson))
+			})
+			// * the requests are recorded then checked from the test goroutine
+			requests := []string{}
+			recordRequest := func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					w.Write([]byte(testCase.Data))
+					return
+				}
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Errorf("Error: %s", err)
+				}
+				requests = append(requests, fmt.Sprintf("%s %s %s", r.Method, r.URL.Path, body))
+				if r.Method == http.MethodPost {
+					w.WriteHeader(http.StatusCreated)
+					w.Write([]byte(`[]`))
+					return
+				}
+				w.Write([]byte(`{}`))
+			}
+			apiHandler.HandleFunc(constants.PLUGIN_CONFIG_URL, recordRequest)
+			apiHandler.HandleFunc(constants.PLUGIN_CONFIG_URL+"/", recordRequest)
+			connectorConfig, err := client.ConnectorService.SetParams(ctx, "MISP", testCase.Input.(map[string]interface{}))
+			if name == "undeclaredParam" {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Error: %s", err)
+				}
+				testWantData(t, "MISP", connectorConfig.Name)
+			}
+			testWantData(t, testCase.Want, requests)
+		})
+	}
+}
+
+func TestConnectorServiceGet(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input:      "MISP",
+		Data:       `{"name":"MISP","python_module":"misp.MISP","disabled":false,"maximum_tlp":"AMBER"}`,
+		StatusCode: http.StatusOK,
+		Want:       gothreatmatrix.AMBER,
+	}
+	testCases["connectorDoesntExist"] = TestData{
+		Input:      "notAConnector",
+		Data:       `{"detail": "Not found."}`,
+		StatusCode: http.StatusNotFound,
+		Want: &gothreatmatrix.Error{
+			StatusCode: http.StatusNotFound,
+			Message:    `{"detail": "Not found."}`,
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			input := testCase.Input.(string)
+			apiHandler.Handle(fmt.Sprintf(constants.SPECIFIC_CONNECTOR_CONFIG_URL, input), serverHandler(t, testCase, "GET"))
+			connectorConfig, err := client.ConnectorService.Get(ctx, input)
+			if err != nil {
+				testError(t, testCase, err)
+			} else {
+				testWantData(t, testCase.Want, connectorConfig.MaximumTlp)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft/go-threatmatrix/constants"
+	"github.com/khulnasoft/go-threatmatrix/gothreatmatrix"
+)
+
+// authorizationRecorder answers the tag list endpoint and records the Authorization headers it received.
+type authorizationRecorder struct {
+	mutex          sync.Mutex
+	authorizations []string
+	rejected       string
+	// onReject runs before the rejected request is answered, e.g to rotate the token meanwhile
+	onReject func()
+}
+
+func (recorder *authorizationRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	authorization := r.Header.Get("Authorization")
+	recorder.authorizations = append(recorder.authorizations, authorization)
+	if authorization == recorder.rejected {
+		if recorder.onReject != nil {
+			recorder.onReject()
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"detail":"Invalid token."}`))
+		return
+	}
+	w.Write([]byte(`[]`))
+}
+
+func TestCredentialProviders(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("file-token-1\n"), 0600); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	t.Setenv("TEST_THREATMATRIX_TOKEN", "env-token")
+	testCases := make(map[string]TestData)
+	testCases["static"] = TestData{
+		Input: gothreatmatrix.NewStaticCredentials("static-token"),
+		Want:  []string{"token static-token"},
+	}
+	testCases["env"] = TestData{
+		Input: gothreatmatrix.EnvCredentials("TEST_THREATMATRIX_TOKEN"),
+		Want:  []string{"token env-token"},
+	}
+	testCases["callback"] = TestData{
+		Input: gothreatmatrix.CredentialFunc(func(ctx context.Context) (string, error) {
+			return "vault-token", nil
+		}),
+		Want: []string{"token vault-token"},
+	}
+	testCases["file"] = TestData{
+		Input: gothreatmatrix.NewFileCredentials(tokenFile),
+		Want:  []string{"token file-token-1"},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			recorder := &authorizationRecorder{}
+			apiHandler.Handle(constants.BASE_TAG_URL, recorder)
+			client.SetCredentialProvider(testCase.Input.(gothreatmatrix.CredentialProvider))
+			if _, err := client.TagService.List(context.Background()); err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			testWantData(t, testCase.Want, recorder.authorizations)
+		})
+	}
+}
+
+func TestFileCredentialsRotation(t *testing.T) {
+	client, apiHandler, closeServer := setup()
+	defer closeServer()
+	ctx := context.Background()
+	recorder := &authorizationRecorder{}
+	apiHandler.Handle(constants.BASE_TAG_URL, recorder)
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("file-token-1\n"), 0600); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	client.SetCredentialProvider(gothreatmatrix.NewFileCredentials(tokenFile))
+	if _, err := client.TagService.List(ctx); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if err := os.WriteFile(tokenFile, []byte("file-token-2\n"), 0600); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	// * make sure the rotation is seen even on coarse modification times
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(tokenFile, later, later); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if _, err := client.TagService.List(ctx); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	// * without a provider the options token is used again
+	client.SetCredentialProvider(nil)
+	if _, err := client.TagService.List(ctx); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	testWantData(t, []string{"token file-token-1", "token file-token-2", "token test-token"}, recorder.authorizations)
+}
+
+func TestCredentialProviderError(t *testing.T) {
+	client, apiHandler, closeServer := setup()
+	defer closeServer()
+	sent := false
+	apiHandler.HandleFunc(constants.BASE_TAG_URL, func(w http.ResponseWriter, r *http.Request) {
+		sent = true
+	})
+	vaultError := errors.New("vault is sealed")
+	client.SetCredentialProvider(gothreatmatrix.CredentialFunc(func(ctx context.Context) (string, error) {
+		return "", vaultError
+	}))
+	if _, err := client.TagService.List(context.Background()); !errors.Is(err, vaultError) {
+		t.Fatalf("expected the provider error, got: %v", err)
+	}
+	// * no request is sent without a token
+	testWantData(t, false, sent)
+}
+
+func TestSessionCredentials(t *testing.T) {
+	apiHandler := http.NewServeMux()
+	testServer := httptest.NewServer(apiHandler)
+	defer testServer.Close()
+	client := NewTestThreatMatrixClient(testServer.URL)
+	ctx := context.Background()
+	logins := 0
+	refreshes := 0
+	// * the logins and refreshes are recorded then checked from the test goroutine
+	loginCredentials := []map[string]string{}
+	refreshAuthorizations := []string{}
+	apiHandler.HandleFunc(constants.LOGIN_URL, func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		credentials := map[string]string{}
+		if err := json.NewDecoder(r.Body).Decode(&credentials); err != nil {
+			t.Errorf("Error: %s", err)
+		}
+		loginCredentials = append(loginCredentials, credentials)
+		logins++
+		tokens := map[int]string{1: "session-1", 2: "session-2", 3: "session-3"}
+		// * the first session is about to expire so it gets refreshed
+		expiry := time.Now().Add(30 * time.Second)
+		if logins > 1 {
+			expiry = time.Now().Add(time.Hour)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"token": tokens[logins], "expiry": expiry})
+	})
+	apiHandler.HandleFunc(constants.REFRESH_TOKEN_URL, func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		refreshAuthorizations = append(refreshAuthorizations, r.Header.Get("Authorization"))
+		refreshes++
+		json.NewEncoder(w).Encode(map[string]interface{}{"expiry": time.Now().Add(time.Hour)})
+	})
+	recorder := &authorizationRecorder{}
+	apiHandler.Handle(constants.BASE_TAG_URL, recorder)
+
+	if _, err := gothreatmatrix.NewSessionCredentials(&gothreatmatrix.SessionCredentialsOptions{Url: testServer.URL}); err == nil {
+		t.Fatalf("expected an error")
+	}
+	session, err := gothreatmatrix.NewSessionCredentials(&gothreatmatrix.SessionCredentialsOptions{
+		Url:      testServer.URL,
+		Username: "hussain",
+		Password: "p4ssw0rd",
+	})
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	client.SetCredentialProvider(session)
+
+	// * logs in then refreshes the expiring token
+	for i := 0; i < 2; i++ {
+		if _, err := client.TagService.List(ctx); err != nil {
+			t.Fatalf("Error: %s", err)
+		}
+	}
+	// * a rejected token drops the session so the next request logs in again
+	recorder.mutex.Lock()
+	recorder.rejected = "token session-1"
+	recorder.mutex.Unlock()
+	if _, err := client.TagService.List(ctx); err == nil {
+		t.Fatalf("expected an error")
+	}
+	if _, err := client.TagService.List(ctx); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	testWantData(t, []string{"token session-1", "token session-1", "token session-1", "token session-2"}, recorder.authorizations)
+	testWantData(t, 2, logins)
+
+	// * a 401 to a token rotated in the meantime keeps the new session
+	recorder.mutex.Lock()
+	recorder.rejected = "token session-2"
+	recorder.onReject = func() {
+		session.Invalidate("session-2")
+		if _, err := session.Token(ctx); err != nil {
+			t.Errorf("Error: %s", err)
+		}
+	}
+	recorder.mutex.Unlock()
+	if _, err := client.TagService.List(ctx); err == nil {
+		t.Fatalf("expected an error")
+	}
+	if _, err := client.TagService.List(ctx); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	testWantData(t, []string{"token session-2", "token session-3"}, recorder.authorizations[4:])
+	testWantData(t, 3, logins)
+	testWantData(t, 1, refreshes)
+	testWantData(t, []string{"token session-1"}, refreshAuthorizations)
+	for _, credentials := range loginCredentials {
+		testWantData(t, map[string]string{"username": "hussain", "password": "p4ssw0rd"}, credentials)
+	}
+}
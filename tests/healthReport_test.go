@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/khulnasoft/go-threatmatrix/constants"
+	"github.com/khulnasoft/go-threatmatrix/gothreatmatrix"
+)
+
+func TestHealthReport(t *testing.T) {
+	analyzerConfigJsonString := `{
+		"Yara": {"name":"Yara","disabled":false,"docker_based":true,"verification":{"configured":true,"missing_secrets":[]}},
+		"Floss": {"name":"Floss","disabled":false,"docker_based":true,"verification":{"configured":true,"missing_secrets":[]}},
+		"Old_Docker": {"name":"Old_Docker","disabled":true,"docker_based":true,"verification":{"configured":true,"missing_secrets":[]}},
+		"Classic_DNS": {"name":"Classic_DNS","disabled":false,"docker_based":false,"verification":{"configured":true,"missing_secrets":[]}},
+		"Shodan_Search": {"name":"Shodan_Search","disabled":false,"docker_based":false,"verification":{"configured":false,"error_message":"api_key_name not set","missing_secrets":["api_key_name"]}}
+	}`
+	connectorConfigJsonString := `{
+		"MISP": {"name":"MISP","disabled":false,"verification":{"configured":false,"error_message":"api_key_name not set","missing_secrets":["api_key_name"]}}
+	}`
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input: &gothreatmatrix.HealthReportOptions{Concurrency: 2},
+		Want: []gothreatmatrix.PluginHealth{
+			{Name: "Floss", Type: "analyzer", Error: "Status Code: 500 \n Error: {\"detail\": \"container down\"}"},
+			{Name: "Yara", Type: "analyzer", Healthy: true},
+			{Name: "MISP", Type: "connector", Healthy: true, Misconfigured: true, MissingSecrets: []string{"api_key_name"}, VerificationError: "api_key_name not set"},
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			apiHandler.HandleFunc(constants.ANALYZER_CONFIG_URL, func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(analyzerConfigJsonString))
+			})
+			apiHandler.HandleFunc(constants.CONNECTOR_CONFIG_URL, func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(connectorConfigJsonString))
+			})
+			apiHandler.HandleFunc(fmt.Sprintf(constants.ANALYZER_HEALTHCHECK_URL, "Yara"), func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"status": true}`))
+			})
+			apiHandler.HandleFunc(fmt.Sprintf(constants.ANALYZER_HEALTHCHECK_URL, "Floss"), func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"detail": "container down"}`))
+			})
+			apiHandler.HandleFunc(fmt.Sprintf(constants.CONNECTOR_HEALTHCHECK_URL, "MISP"), func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"status": true}`))
+			})
+			options := testCase.Input.(*gothreatmatrix.HealthReportOptions)
+			report, err := client.HealthReport(ctx, options)
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			// * latencies depend on the machine running the tests
+			for index := range report.Plugins {
+				if report.Plugins[index].LatencyMs <= 0 {
+					t.Fatalf("latency of %s was not measured", report.Plugins[index].Name)
+				}
+				report.Plugins[index].LatencyMs = 0
+			}
+			testWantData(t, testCase.Want, report.Plugins)
+			// * Shodan_Search has no health check so it only counts as misconfigured
+			testWantData(t, []int{2, 1, 2}, []int{report.Healthy, report.Unhealthy, report.Misconfigured})
+			reportJson, err := json.Marshal(report)
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			decoded := gothreatmatrix.HealthReport{}
+			if err := json.Unmarshal(reportJson, &decoded); err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			testWantData(t, report.Plugins, decoded.Plugins)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/khulnasoft/go-threatmatrix/constants"
+	"github.com/khulnasoft/go-threatmatrix/gothreatmatrix"
+)
+
+const threatFoxIngestorJson = `{"name":"ThreatFox","python_module":"threatfox.ThreatFox","disabled":false,"params":{"days":{"value":1,"type":"int"}},"secrets":{},"schedule":{"minute":"0","hour":"*/6","day_of_week":"*","day_of_month":"*","month_of_year":"*"},"playbook_to_execute":"Popular_IP_Reputation_Services","maximum_jobs":50,"user":{"username":"ThreatFoxIngestor"}}`
+
+func TestIngestorServiceGetConfigs(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Data:       `{"ThreatFox":` + threatFoxIngestorJson + `,"MalwareBazaar":{"name":"MalwareBazaar","disabled":true,"schedule":{"minute":"30","hour":"1","day_of_week":"*","day_of_month":"*","month_of_year":"*"}}}`,
+		StatusCode: http.StatusOK,
+		Want:       []string{"MalwareBazaar: 30 1 * * *", "ThreatFox: 0 */6 * * *"},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			apiHandler.Handle(constants.INGESTOR_CONFIG_URL, serverHandler(t, testCase, "GET"))
+			ingestorConfigs, err := client.IngestorService.GetConfigs(ctx)
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			schedules := []string{}
+			for _, ingestorConfig := range *ingestorConfigs {
+				schedules = append(schedules, ingestorConfig.Name+": "+ingestorConfig.Schedule.String())
+			}
+			testWantData(t, testCase.Want, schedules)
+		})
+	}
+}
+
+func TestIngestorServiceUpdateSchedule(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input: "15 */2 * * 1-5",
+		Want: map[string]interface{}{"schedule": map[string]interface{}{
+			"minute": "15", "hour": "*/2", "day_of_month": "*", "month_of_year": "*", "day_of_week": "1-5",
+		}},
+	}
+	testCases["invalid"] = TestData{
+		Input: "*/5 * *",
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			body := map[string]interface{}{}
+			apiHandler.HandleFunc(fmt.Sprintf(constants.SPECIFIC_INGESTOR_CONFIG_URL, "ThreatFox"), func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "PATCH")
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("Error: %s", err)
+				}
+				w.Write([]byte(threatFoxIngestorJson))
+			})
+			schedule, err := gothreatmatrix.ParseCrontab(testCase.Input.(string))
+			if testCase.Want == nil {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				// * an incomplete schedule is rejected before any request
+				if _, err := client.IngestorService.UpdateSchedule(ctx, "ThreatFox", gothreatmatrix.CrontabSchedule{Minute: "0"}); err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			ingestorConfig, err := client.IngestorService.UpdateSchedule(ctx, "ThreatFox", schedule)
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			testWantData(t, "ThreatFox", ingestorConfig.Name)
+			testWantData(t, testCase.Want, body)
+		})
+	}
+}
+
+func TestIngestorServiceSetParams(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input: map[string]interface{}{"days": 3},
+		Want:  []map[string]interface{}{{"attribute": "days", "value": float64(3), "for_organization": true, "ingestor_config": "ThreatFox"}},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			apiHandler.HandleFunc(fmt.Sprintf(constants.SPECIFIC_INGESTOR_CONFIG_URL, "ThreatFox"), func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(threatFoxIngestorJson))
+			})
+			var posted []map[string]interface{}
+			var decodeError error
+			apiHandler.HandleFunc(constants.PLUGIN_CONFIG_URL, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					w.Write([]byte(`[]`))
+					return
+				}
+				testMethod(t, r, "POST")
+				decodeError = json.NewDecoder(r.Body).Decode(&posted)
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`[]`))
+			})
+			if _, err := client.IngestorService.SetParams(ctx, "ThreatFox", testCase.Input.(map[string]interface{})); err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			if decodeError != nil {
+				t.Fatalf("Error: %s", decodeError)
+			}
+			testWantData(t, testCase.Want, posted)
+			if _, err := client.IngestorService.SetParams(ctx, "ThreatFox", map[string]interface{}{"weeks": 1}); err == nil {
+				t.Fatalf("expected an error")
+			}
+		})
+	}
+}
+
+func TestIngestorServiceListJobs(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Data: `{"count":3,"total_pages":1,"results":[{"id":1,"user":{"username":"ThreatFoxIngestor"}},{"id":2,"user":{"username":"ThreatFoxIngestor2"}},{"id":3,"user":{"username":"ThreatFoxIngestor"}}]}`,
+		Want: []int{1, 3},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			apiHandler.HandleFunc(fmt.Sprintf(constants.SPECIFIC_INGESTOR_CONFIG_URL, "ThreatFox"), func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(threatFoxIngestorJson))
+			})
+			apiHandler.HandleFunc(constants.BASE_JOB_URL, func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "GET")
+				// * only the jobs of the ingestor's user are listed
+				if r.URL.Query().Get("user") != "ThreatFoxIngestor" {
+					w.Write([]byte(`{"count":0,"total_pages":1,"results":[]}`))
+					return
+				}
+				w.Write([]byte(testCase.Data))
+			})
+			jobs, err := client.IngestorService.ListJobs(ctx, "ThreatFox")
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			jobIds := []int{}
+			for _, job := range jobs {
+				jobIds = append(jobIds, job.ID)
+			}
+			testWantData(t, testCase.Want, jobIds)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/khulnasoft/go-threatmatrix/constants"
+	"github.com/khulnasoft/go-threatmatrix/gothreatmatrix"
+)
+
+func TestInvestigationServiceCreate(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input: gothreatmatrix.InvestigationParams{
+			Name:        "phishing campaign",
+			Description: "emails of the 2nd of May",
+			Tags:        []string{"phishing"},
+		},
+		Data:       `{"id":3,"name":"phishing campaign","description":"emails of the 2nd of May","owner":"analyst","tags":["phishing"],"tlp":"AMBER","status":"created","jobs":[],"total_jobs":0}`,
+		StatusCode: http.StatusCreated,
+		Want: &gothreatmatrix.Investigation{
+			ID:          3,
+			Name:        "phishing campaign",
+			Description: "emails of the 2nd of May",
+			Owner:       gothreatmatrix.InvestigationOwner{Username: "analyst"},
+			Tags:        []string{"phishing"},
+			Tlp:         gothreatmatrix.AMBER,
+			Status:      gothreatmatrix.InvestigationCreated,
+			Jobs:        []uint64{},
+		},
+	}
+	testCases["invalidStatus"] = TestData{
+		Input: gothreatmatrix.InvestigationParams{
+			Name:   "phishing campaign",
+			Status: "archived",
+		},
+	}
+	testCases["noName"] = TestData{
+		Input: gothreatmatrix.InvestigationParams{},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			apiHandler.Handle(constants.BASE_INVESTIGATION_URL, serverHandler(t, testCase, "POST"))
+			investigationParams := testCase.Input.(gothreatmatrix.InvestigationParams)
+			investigation, err := client.InvestigationService.Create(ctx, &investigationParams)
+			if testCase.Want == nil {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			testWantData(t, testCase.Want, investigation)
+		})
+	}
+}
+
+func TestInvestigationServiceListAll(t *testing.T) {
+	client, apiHandler, closeServer := setup()
+	defer closeServer()
+	ctx := context.Background()
+	pages := map[string]string{
+		"1": `{"count":3,"total_pages":2,"results":[{"id":1,"name":"first","owner":{"username":"analyst"},"status":"concluded"},{"id":2,"name":"second","owner":"analyst","status":"running"}]}`,
+		"2": `{"count":3,"total_pages":2,"results":[{"id":3,"name":"third","owner":"admin","status":"created"}]}`,
+	}
+	apiHandler.HandleFunc(constants.BASE_INVESTIGATION_URL, func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		w.Write([]byte(pages[r.URL.Query().Get("page")]))
+	})
+	investigations, err := client.InvestigationService.ListAll(ctx)
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	summaries := []string{}
+	for _, investigation := range investigations {
+		summaries = append(summaries, fmt.Sprintf("%d %s %s %s", investigation.ID, investigation.Name, investigation.Owner.Username, investigation.Status))
+	}
+	want := []string{
+		"1 first analyst concluded",
+		"2 second analyst running",
+		"3 third admin created",
+	}
+	testWantData(t, want, summaries)
+}
+
+func TestInvestigationServiceAddJob(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input:      uint64(42),
+		Data:       `{}`,
+		StatusCode: http.StatusOK,
+		Want:       true,
+	}
+	testCases["notFound"] = TestData{
+		Input:      uint64(42),
+		Data:       `{"detail": "Not found."}`,
+		StatusCode: http.StatusNotFound,
+		Want: &gothreatmatrix.Error{
+			StatusCode: http.StatusNotFound,
+			Message:    `{"detail": "Not found."}`,
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			body := map[string]interface{}{}
+			apiHandler.HandleFunc(fmt.Sprintf(constants.ADD_JOB_INVESTIGATION_URL, 3), func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "POST")
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("Error: %s", err)
+				}
+				w.WriteHeader(testCase.StatusCode)
+				w.Write([]byte(testCase.Data))
+			})
+			added, err := client.InvestigationService.AddJob(ctx, 3, testCase.Input.(uint64))
+			testWantData(t, map[string]interface{}{"job": float64(42)}, body)
+			if testCase.StatusCode < http.StatusOK || testCase.StatusCode >= http.StatusBadRequest {
+				testError(t, testCase, err)
+				return
+			}
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			testWantData(t, testCase.Want, added)
+		})
+	}
+	client, _, closeServer := setup()
+	defer closeServer()
+	if _, err := client.InvestigationService.RemoveJob(context.Background(), 3, 0); err == nil {
+		t.Fatalf("expected an error")
+	}
+}
+
+func TestInvestigationServiceTree(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Data:       `{"name":"phishing campaign","owner":"analyst","status":"running","jobs":[{"pk":10,"analyzed_object_name":"evil.example","playbook":"Dns","status":"reported_without_fails","children":[{"pk":11,"analyzed_object_name":"1.2.3.4","playbook":"Popular_IP_Reputation_Services","status":"running"}]}]}`,
+		StatusCode: http.StatusOK,
+		Want: &gothreatmatrix.InvestigationTree{
+			Name:   "phishing campaign",
+			Owner:  gothreatmatrix.InvestigationOwner{Username: "analyst"},
+			Status: gothreatmatrix.InvestigationRunning,
+			Jobs: []gothreatmatrix.InvestigationTreeJob{
+				{
+					ID:                 10,
+					AnalyzedObjectName: "evil.example",
+					Playbook:           "Dns",
+					Status:             "reported_without_fails",
+					Children: []gothreatmatrix.InvestigationTreeJob{
+						{
+							ID:                 11,
+							AnalyzedObjectName: "1.2.3.4",
+							Playbook:           "Popular_IP_Reputation_Services",
+							Status:             "running",
+						},
+					},
+				},
+			},
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			apiHandler.Handle(fmt.Sprintf(constants.INVESTIGATION_TREE_URL, 3), serverHandler(t, testCase, "GET"))
+			tree, err := client.InvestigationService.Tree(ctx, 3)
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			testWantData(t, testCase.Want, tree)
+		})
+	}
+}
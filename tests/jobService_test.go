@@ -1,12 +1,23 @@
 package tests
 
 import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
 	"context"
+	"crypto/md5"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/crc32"
+	"io"
 	"net/http"
+	"os"
+	"path"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/khulnasoft/go-threatmatrix/constants"
 	"github.com/khulnasoft/go-threatmatrix/gothreatmatrix"
 )
@@ -387,3 +398,519 @@ func TestJobServiceRetryConnector(t *testing.T) {
 		})
 	}
 }
+
+func TestJobServiceRescan(t *testing.T) {
+	// *table test case
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input:      72,
+		Data:       `{"id":73}`,
+		StatusCode: http.StatusOK,
+		Want:       73,
+	}
+	testCases["notFound"] = TestData{
+		Input:      300,
+		Data:       `{"detail":"Not found."}`,
+		StatusCode: http.StatusNotFound,
+		Want: &gothreatmatrix.Error{
+			StatusCode: http.StatusNotFound,
+			Message:    `{"detail":"Not found."}`,
+		},
+	}
+	for name, testCase := range testCases {
+		//* Subtest
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			id, ok := testCase.Input.(int)
+			if ok {
+				jobId := uint64(id)
+				testUrl := fmt.Sprintf(constants.RESCAN_JOB_URL, jobId)
+				apiHandler.Handle(testUrl, serverHandler(t, testCase, "POST"))
+				newJobId, err := client.JobService.Rescan(ctx, jobId)
+				if err != nil {
+					testError(t, testCase, err)
+				} else {
+					testWantData(t, testCase.Want, newJobId)
+				}
+			}
+		})
+	}
+}
+
+func TestJobServiceResubmit(t *testing.T) {
+	observableJobJson := `{"id":72,"tags":[{"id":1,"label":"triage","color":"#fff"}],"is_sample":false,"observable_name":"8.8.8.8","observable_classification":"ip","analyzers_requested":["Classic_DNS"],"connectors_requested":[],"tlp":"AMBER"}`
+	fileJobJson := `{"id":80,"tags":[],"is_sample":true,"md5":"5eb63bbbe01eeed093cb22bb8f5acdc3","file_name":"sample.txt","analyzers_requested":["File_Info"],"connectors_requested":[],"tlp":"WHITE"}`
+	analysisJson := `{"job_id":81,"status":"accepted","warnings":[],"analyzers_running":["File_Info"],"connectors_running":[]}`
+	analysisResponse := gothreatmatrix.AnalysisResponse{}
+	if unmarshalError := json.Unmarshal([]byte(analysisJson), &analysisResponse); unmarshalError != nil {
+		t.Fatalf("Error: %s", unmarshalError)
+	}
+	testCases := make(map[string]TestData)
+	testCases["observable"] = TestData{
+		Input:      observableJobJson,
+		Data:       analysisJson,
+		StatusCode: http.StatusOK,
+		Want:       &analysisResponse,
+	}
+	testCases["file"] = TestData{
+		Input:      fileJobJson,
+		Data:       analysisJson,
+		StatusCode: http.StatusOK,
+		Want:       &analysisResponse,
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			jobJson, ok := testCase.Input.(string)
+			if !ok {
+				t.Fatalf("Casting failed!")
+			}
+			job := gothreatmatrix.Job{}
+			if unmarshalError := json.Unmarshal([]byte(jobJson), &job); unmarshalError != nil {
+				t.Fatalf("Error: %s", unmarshalError)
+			}
+			jobId := uint64(job.ID)
+			apiHandler.HandleFunc(fmt.Sprintf(constants.SPECIFIC_JOB_URL, jobId), func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "GET")
+				w.Write([]byte(jobJson))
+			})
+			apiHandler.HandleFunc(fmt.Sprintf(constants.DOWNLOAD_SAMPLE_JOB_URL, jobId), func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "GET")
+				w.Write([]byte("hello world"))
+			})
+			// * the submitted params are recorded then checked from the test goroutine
+			submitted := ""
+			apiHandler.HandleFunc(constants.ANALYZE_OBSERVABLE_URL, func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "POST")
+				params := map[string]interface{}{}
+				if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+					t.Errorf("Error: %s", err)
+				}
+				submitted = fmt.Sprint(params["observable_name"], " ", params["tlp"], " ", params["tags_labels"])
+				w.Write([]byte(testCase.Data))
+			})
+			apiHandler.HandleFunc(constants.ANALYZE_FILE_URL, func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "POST")
+				file, header, err := r.FormFile("file")
+				if err != nil {
+					t.Errorf("Error: %s", err)
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				defer file.Close()
+				submitted = fmt.Sprint(header.Filename, " ", r.MultipartForm.Value["analyzers_requested"])
+				w.Write([]byte(testCase.Data))
+			})
+			overrides := &gothreatmatrix.BasicAnalysisParams{}
+			wantSubmitted := "sample.txt [File_Info]"
+			if !job.IsSample {
+				overrides.Tlp = gothreatmatrix.GREEN
+				wantSubmitted = "8.8.8.8 GREEN [triage]"
+			}
+			gottenAnalysisResponse, err := client.JobService.Resubmit(ctx, jobId, overrides)
+			if err != nil {
+				testError(t, testCase, err)
+			} else {
+				testWantData(t, testCase.Want, gottenAnalysisResponse)
+				testWantData(t, wantSubmitted, submitted)
+			}
+		})
+	}
+}
+
+func TestJobServiceBulkKill(t *testing.T) {
+	jobListJson := `{"count":3,"total_pages":1,"results":[{"id":1,"status":"running"},{"id":2,"status":"reported_without_fails"},{"id":3,"status":"running"}]}`
+	// *table test case
+	testCases := make(map[string]TestData)
+	testCases["ids"] = TestData{
+		Input: gothreatmatrix.BulkJobParams{
+			JobIDs:      []uint64{1, 2, 2},
+			Concurrency: 2,
+		},
+		Want: map[uint64]gothreatmatrix.BulkResult{
+			1: {Success: true},
+			2: {Success: true},
+		},
+	}
+	testCases["filter"] = TestData{
+		Input: gothreatmatrix.BulkJobParams{
+			JobIDs: []uint64{4},
+			Filter: func(job *gothreatmatrix.JobList) bool {
+				return job.Status == "running"
+			},
+		},
+		Want: map[uint64]gothreatmatrix.BulkResult{
+			1: {Success: true},
+			3: {Success: true},
+			4: {Success: false, Error: &gothreatmatrix.Error{
+				StatusCode: http.StatusNotFound,
+				Message:    `{"detail":"Not found."}`,
+			}},
+		},
+	}
+	for name, testCase := range testCases {
+		//* Subtest
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			apiHandler.HandleFunc(constants.BASE_JOB_URL, func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "GET")
+				w.Write([]byte(jobListJson))
+			})
+			for _, jobId := range []uint64{1, 2, 3} {
+				apiHandler.Handle(fmt.Sprintf(constants.KILL_JOB_URL, jobId), serverHandler(t, TestData{StatusCode: http.StatusNoContent}, "PATCH"))
+			}
+			apiHandler.Handle(fmt.Sprintf(constants.KILL_JOB_URL, 4), serverHandler(t, TestData{StatusCode: http.StatusNotFound, Data: `{"detail":"Not found."}`}, "PATCH"))
+			params, ok := testCase.Input.(gothreatmatrix.BulkJobParams)
+			if ok {
+				results, err := client.JobService.BulkKill(ctx, &params)
+				if err != nil {
+					t.Fatalf("Error: %s", err)
+				}
+				diff := cmp.Diff(testCase.Want, results, cmpopts.IgnoreFields(gothreatmatrix.Error{}, "Response"))
+				if diff != "" {
+					t.Fatalf("%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestJobServiceRetryFailedAnalyzers(t *testing.T) {
+	jobJson := `{"id":72,"analyzer_reports":[{"name":"Classic_DNS","status":"SUCCESS"},{"name":"Darksearch_Query","status":"FAILED"},{"name":"GoogleWebRisk","status":"FAILED"}]}`
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input: gothreatmatrix.BulkJobParams{
+			JobIDs: []uint64{72},
+		},
+		Want: map[uint64]gothreatmatrix.RetryFailedResult{
+			72: {
+				Analyzers: map[string]gothreatmatrix.BulkResult{
+					"Darksearch_Query": {Success: true},
+					"GoogleWebRisk":    {Success: true},
+				},
+			},
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			apiHandler.Handle(fmt.Sprintf(constants.SPECIFIC_JOB_URL, 72), serverHandler(t, TestData{StatusCode: http.StatusOK, Data: jobJson}, "GET"))
+			for _, analyzerName := range []string{"Darksearch_Query", "GoogleWebRisk"} {
+				testUrl := fmt.Sprintf(constants.RETRY_ANALYZER_JOB_URL, 72, analyzerName)
+				apiHandler.Handle(testUrl, serverHandler(t, TestData{StatusCode: http.StatusNoContent}, "PATCH"))
+			}
+			params, ok := testCase.Input.(gothreatmatrix.BulkJobParams)
+			if ok {
+				results, err := client.JobService.RetryFailedAnalyzers(ctx, &params)
+				if err != nil {
+					t.Fatalf("Error: %s", err)
+				}
+				testWantData(t, testCase.Want, results)
+			}
+		})
+	}
+}
+
+func sampleHandler(t *testing.T, sample string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		var offset int
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &offset); err == nil {
+			if offset >= len(sample) {
+				w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+				return
+			}
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte(sample[offset:]))
+			return
+		}
+		w.Write([]byte(sample))
+	}
+}
+
+func TestJobServiceDownloadSampleToWriter(t *testing.T) {
+	sample := "This is the sample"
+	sampleMd5 := fmt.Sprintf("%x", md5.Sum([]byte(sample)))
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input: gothreatmatrix.SampleDownloadOptions{Md5: sampleMd5},
+		Want:  sample,
+	}
+	testCases["md5FromJob"] = TestData{
+		Input: gothreatmatrix.SampleDownloadOptions{},
+		Want:  sample,
+	}
+	testCases["corrupted"] = TestData{
+		Input: gothreatmatrix.SampleDownloadOptions{Md5: "5eb63bbbe01eeed093cb22bb8f5acdc3"},
+		Want:  gothreatmatrix.ErrSampleIntegrity,
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			apiHandler.Handle(fmt.Sprintf(constants.SPECIFIC_JOB_URL, 1), serverHandler(t, TestData{StatusCode: http.StatusOK, Data: fmt.Sprintf(`{"id":1,"md5":"%s"}`, sampleMd5)}, "GET"))
+			apiHandler.HandleFunc(fmt.Sprintf(constants.DOWNLOAD_SAMPLE_JOB_URL, 1), sampleHandler(t, sample))
+			options, ok := testCase.Input.(gothreatmatrix.SampleDownloadOptions)
+			if ok {
+				buffer := &bytes.Buffer{}
+				_, err := client.JobService.DownloadSampleToWriter(ctx, 1, buffer, &options)
+				if wantError, isError := testCase.Want.(error); isError {
+					if !errors.Is(err, wantError) {
+						t.Fatalf("Error: %v, want %v", err, wantError)
+					}
+				} else if err != nil {
+					t.Fatalf("Error: %s", err)
+				} else {
+					testWantData(t, testCase.Want, buffer.String())
+				}
+			}
+		})
+	}
+}
+
+func TestJobServiceDownloadSampleToFile(t *testing.T) {
+	sample := "This is the sample"
+	sampleMd5 := fmt.Sprintf("%x", md5.Sum([]byte(sample)))
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input: "",
+		Want:  sample,
+	}
+	testCases["resume"] = TestData{
+		Input: sample[:7],
+		Want:  sample,
+	}
+	testCases["complete"] = TestData{
+		Input: sample,
+		Want:  sample,
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			apiHandler.HandleFunc(fmt.Sprintf(constants.DOWNLOAD_SAMPLE_JOB_URL, 1), sampleHandler(t, sample))
+			partialSample, ok := testCase.Input.(string)
+			if ok {
+				filePath := path.Join(t.TempDir(), "sample")
+				if err := os.WriteFile(filePath, []byte(partialSample), 0600); err != nil {
+					t.Fatalf("Error: %s", err)
+				}
+				size, err := client.JobService.DownloadSampleToFile(ctx, 1, filePath, &gothreatmatrix.SampleDownloadOptions{Md5: sampleMd5})
+				if err != nil {
+					t.Fatalf("Error: %s", err)
+				}
+				gottenSample, err := os.ReadFile(filePath)
+				if err != nil {
+					t.Fatalf("Error: %s", err)
+				}
+				testWantData(t, int64(len(sample)), size)
+				testWantData(t, testCase.Want, string(gottenSample))
+			}
+		})
+	}
+}
+
+// decryptZipCryptoEntry reads an entry protected by ZipCrypto the way unzip tools do, checking the password through the check byte.
+func decryptZipCryptoEntry(t *testing.T, entry *zip.File, password string) string {
+	t.Helper()
+	rawReader, err := entry.OpenRaw()
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	encrypted, err := io.ReadAll(rawReader)
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	keys := [3]uint32{0x12345678, 0x23456789, 0x34567890}
+	update := func(b byte) {
+		keys[0] = (keys[0] >> 8) ^ crc32.IEEETable[byte(keys[0])^b]
+		keys[1] = (keys[1]+(keys[0]&0xff))*134775813 + 1
+		keys[2] = (keys[2] >> 8) ^ crc32.IEEETable[byte(keys[2])^byte(keys[1]>>24)]
+	}
+	for index := 0; index < len(password); index++ {
+		update(password[index])
+	}
+	decrypted := make([]byte, len(encrypted))
+	for index, b := range encrypted {
+		temp := uint16(keys[2] | 2)
+		decrypted[index] = b ^ byte((temp*(temp^1))>>8)
+		update(decrypted[index])
+	}
+	testWantData(t, byte(entry.ModifiedTime>>8), decrypted[11])
+	plain, err := io.ReadAll(flate.NewReader(bytes.NewReader(decrypted[12:])))
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	testWantData(t, entry.CRC32, crc32.ChecksumIEEE(plain))
+	return string(plain)
+}
+
+func TestJobServiceDownloadSampleToZip(t *testing.T) {
+	sample := "This is the sample"
+	sampleMd5 := fmt.Sprintf("%x", md5.Sum([]byte(sample)))
+	client, apiHandler, closeServer := setup()
+	defer closeServer()
+	ctx := context.Background()
+	apiHandler.Handle(fmt.Sprintf(constants.SPECIFIC_JOB_URL, 1), serverHandler(t, TestData{StatusCode: http.StatusOK, Data: `{"id":1,"file_name":"sample.exe"}`}, "GET"))
+	apiHandler.HandleFunc(fmt.Sprintf(constants.DOWNLOAD_SAMPLE_JOB_URL, 1), sampleHandler(t, sample))
+	buffer := &bytes.Buffer{}
+	_, err := client.JobService.DownloadSampleToZip(ctx, 1, buffer, &gothreatmatrix.SampleDownloadOptions{Md5: sampleMd5})
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	zipReader, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	testWantData(t, 1, len(zipReader.File))
+	entry := zipReader.File[0]
+	testWantData(t, "sample.exe", entry.Name)
+	testWantData(t, uint16(1), entry.Flags&0x1)
+	testWantData(t, uint64(len(sample)), entry.UncompressedSize64)
+	if bytes.Contains(buffer.Bytes(), []byte(sample)) {
+		t.Fatalf("The sample was written unencrypted")
+	}
+	testWantData(t, sample, decryptZipCryptoEntry(t, entry, gothreatmatrix.DefaultSamplePassword))
+
+	// * a corrupted sample leaves an archive no tool can open
+	corrupted := &bytes.Buffer{}
+	_, err = client.JobService.DownloadSampleToZip(ctx, 1, corrupted, &gothreatmatrix.SampleDownloadOptions{Md5: "5eb63bbbe01eeed093cb22bb8f5acdc3"})
+	if !errors.Is(err, gothreatmatrix.ErrSampleIntegrity) {
+		t.Fatalf("Error: %v, want %v", err, gothreatmatrix.ErrSampleIntegrity)
+	}
+	if _, err := zip.NewReader(bytes.NewReader(corrupted.Bytes()), int64(corrupted.Len())); err == nil {
+		t.Fatalf("The corrupted archive was finalized")
+	}
+}
+
+func TestJobServiceSetTags(t *testing.T) {
+	jobJson := `{"id":72,"tags":[{"id":1,"label":"TEST1","color":"#1c71d8"},{"id":2,"label":"TEST2","color":"#1c71d7"}]}`
+	job := gothreatmatrix.Job{}
+	if unmarshalError := json.Unmarshal([]byte(jobJson), &job); unmarshalError != nil {
+		t.Fatalf("Error: %s", unmarshalError)
+	}
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input:      []uint64{1, 2},
+		Data:       jobJson,
+		StatusCode: http.StatusOK,
+		Want:       &job,
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			tagIds, ok := testCase.Input.([]uint64)
+			if ok {
+				// * the patched tags are recorded then checked from the test goroutine
+				params := map[string][]uint64{}
+				apiHandler.HandleFunc(fmt.Sprintf(constants.SPECIFIC_JOB_URL, 72), func(w http.ResponseWriter, r *http.Request) {
+					testMethod(t, r, "PATCH")
+					if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+						t.Errorf("Error: %s", err)
+					}
+					w.Write([]byte(testCase.Data))
+				})
+				gottenJob, err := client.JobService.SetTags(ctx, 72, tagIds)
+				if err != nil {
+					testError(t, testCase, err)
+				} else {
+					testWantData(t, testCase.Want, gottenJob)
+					testWantData(t, tagIds, params["tags"])
+				}
+			}
+		})
+	}
+}
+
+func TestJobServiceAddTagsByLabel(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["autoCreate"] = TestData{
+		Input: []string{"test1", "NEW"},
+		Want:  []uint64{2, 1, 3},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			labels, ok := testCase.Input.([]string)
+			if ok {
+				apiHandler.HandleFunc(constants.BASE_TAG_URL, func(w http.ResponseWriter, r *http.Request) {
+					if r.Method == http.MethodPost {
+						w.Write([]byte(`{"id":3,"label":"NEW","color":"#1c71d8"}`))
+						return
+					}
+					w.Write([]byte(`[{"id":1,"label":"TEST1","color":"#1c71d8"},{"id":2,"label":"TEST2","color":"#1c71d7"}]`))
+				})
+				// * the patched tags are recorded then checked from the test goroutine
+				params := map[string][]uint64{}
+				apiHandler.HandleFunc(fmt.Sprintf(constants.SPECIFIC_JOB_URL, 72), func(w http.ResponseWriter, r *http.Request) {
+					if r.Method == http.MethodGet {
+						w.Write([]byte(`{"id":72,"tags":[{"id":2,"label":"TEST2","color":"#1c71d7"}]}`))
+						return
+					}
+					testMethod(t, r, "PATCH")
+					if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+						t.Errorf("Error: %s", err)
+					}
+					w.Write([]byte(`{"id":72}`))
+				})
+				_, err := client.JobService.AddTagsByLabel(ctx, 72, labels, true)
+				if err != nil {
+					t.Fatalf("Error: %s", err)
+				}
+				testWantData(t, testCase.Want, params["tags"])
+			}
+		})
+	}
+}
+
+func TestJobServiceListByTag(t *testing.T) {
+	jobListJson := `{"count":3,"total_pages":1,"results":[{"id":1,"tags":[{"id":1,"label":"phishing","color":"#fff"}]},{"id":2,"tags":[]},{"id":3,"tags":[{"id":1,"label":"phishing","color":"#fff"}]}]}`
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input:      "PHISHING",
+		Data:       jobListJson,
+		StatusCode: http.StatusOK,
+		Want:       []int{1, 3},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			queriedLabel := ""
+			apiHandler.HandleFunc(constants.BASE_JOB_URL, func(w http.ResponseWriter, r *http.Request) {
+				queriedLabel = r.URL.Query().Get("tags__label")
+				serverHandler(t, testCase, "GET").ServeHTTP(w, r)
+			})
+			label, ok := testCase.Input.(string)
+			if ok {
+				jobs, err := client.JobService.ListByTag(ctx, label)
+				// * the filtering is pushed to ThreatMatrix
+				testWantData(t, label, queriedLabel)
+				if err != nil {
+					testError(t, testCase, err)
+				} else {
+					jobIds := []int{}
+					for _, job := range jobs {
+						jobIds = append(jobIds, job.ID)
+					}
+					testWantData(t, testCase.Want, jobIds)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/khulnasoft/go-threatmatrix/constants"
+	"github.com/khulnasoft/go-threatmatrix/gothreatmatrix"
+)
+
+// newTestInstance starts a ThreatMatrix server answering through apiHandler and returns an Instance talking to it.
+func newTestInstance(t *testing.T, name string, apiHandler *http.ServeMux) gothreatmatrix.Instance {
+	t.Helper()
+	testServer := httptest.NewServer(apiHandler)
+	t.Cleanup(testServer.Close)
+	client := NewTestThreatMatrixClient(testServer.URL)
+	return gothreatmatrix.Instance{Name: name, Client: &client}
+}
+
+// newDownInstance returns an Instance whose server is not listening anymore.
+func newDownInstance(t *testing.T, name string) gothreatmatrix.Instance {
+	t.Helper()
+	testServer := httptest.NewServer(http.NewServeMux())
+	testServer.Close()
+	client := NewTestThreatMatrixClient(testServer.URL)
+	return gothreatmatrix.Instance{Name: name, Client: &client}
+}
+
+func TestMultiClientFailover(t *testing.T) {
+	var euCalls, usCalls int32
+	euHandler := http.NewServeMux()
+	euHandler.HandleFunc(constants.ANALYZE_OBSERVABLE_URL, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&euCalls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	usHandler := http.NewServeMux()
+	usHandler.HandleFunc(constants.ANALYZE_OBSERVABLE_URL, func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		atomic.AddInt32(&usCalls, 1)
+		w.Write([]byte(`{"job_id":260,"status":"accepted"}`))
+	})
+	multiClient, err := gothreatmatrix.NewMultiClient([]gothreatmatrix.Instance{
+		newDownInstance(t, "lab"),
+		newTestInstance(t, "eu", euHandler),
+		newTestInstance(t, "us", usHandler),
+	}, &gothreatmatrix.MultiClientOptions{FailureThreshold: 1})
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	ctx := context.Background()
+	params := &gothreatmatrix.ObservableAnalysisParams{ObservableName: "192.168.69.42"}
+	for i := 0; i < 2; i++ {
+		analysis, err := multiClient.CreateObservableAnalysis(ctx, params)
+		if err != nil {
+			t.Fatalf("Error: %s", err)
+		}
+		testWantData(t, "us 260", fmt.Sprintf("%s %d", analysis.Instance, analysis.JobID))
+	}
+	// * the down instances are tried last once they failed
+	testWantData(t, int32(1), atomic.LoadInt32(&euCalls))
+	testWantData(t, int32(2), atomic.LoadInt32(&usCalls))
+	healthy := []string{}
+	for _, health := range multiClient.Health() {
+		healthy = append(healthy, fmt.Sprintf("%s %t", health.Name, health.Healthy))
+	}
+	testWantData(t, []string{"lab false", "eu false", "us true"}, healthy)
+}
+
+func TestMultiClientSubmitError(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["invalidParams"] = TestData{
+		Data:       `{"errors":{"observable_name":"invalid"}}`,
+		StatusCode: http.StatusBadRequest,
+	}
+	// * the job may have been created before the gateway failed
+	testCases["badGateway"] = TestData{
+		Data:       `{"detail":"bad gateway"}`,
+		StatusCode: http.StatusBadGateway,
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var usCalls int32
+			euHandler := http.NewServeMux()
+			euHandler.Handle(constants.ANALYZE_OBSERVABLE_URL, serverHandler(t, testCase, "POST"))
+			usHandler := http.NewServeMux()
+			usHandler.HandleFunc(constants.ANALYZE_OBSERVABLE_URL, func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&usCalls, 1)
+			})
+			multiClient, err := gothreatmatrix.NewMultiClient([]gothreatmatrix.Instance{
+				newTestInstance(t, "eu", euHandler),
+				newTestInstance(t, "us", usHandler),
+			}, nil)
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			_, err = multiClient.CreateObservableAnalysis(context.Background(), &gothreatmatrix.ObservableAnalysisParams{})
+			threatMatrixError := &gothreatmatrix.Error{}
+			if !errors.As(err, &threatMatrixError) || threatMatrixError.StatusCode != testCase.StatusCode {
+				t.Fatalf("expected a %d error, got: %v", testCase.StatusCode, err)
+			}
+			testWantData(t, int32(0), atomic.LoadInt32(&usCalls))
+			testWantData(t, true, multiClient.Health()[0].Healthy)
+		})
+	}
+
+	instances := []gothreatmatrix.Instance{newDownInstance(t, "eu"), newDownInstance(t, "eu")}
+	if _, err := gothreatmatrix.NewMultiClient(instances, nil); err == nil {
+		t.Fatalf("expected an error")
+	}
+}
+
+func TestMultiClientSearchJobs(t *testing.T) {
+	// * ThreatMatrix matches the observable name as a substring
+	jobsHandler := func(jobs map[string]string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			testMethod(t, r, "GET")
+			w.Write([]byte(jobs[r.URL.Query().Get("observable_name")]))
+		}
+	}
+	euHandler := http.NewServeMux()
+	euHandler.HandleFunc(constants.BASE_JOB_URL, jobsHandler(map[string]string{
+		"8.8.8.8": `{"count":2,"total_pages":1,"results":[{"id":1,"observable_name":"8.8.8.8","received_request_time":"2023-05-01T10:00:00Z"},{"id":2,"observable_name":"8.8.8.88","received_request_time":"2023-05-02T10:00:00Z"}]}`,
+	}))
+	usHandler := http.NewServeMux()
+	usHandler.HandleFunc(constants.BASE_JOB_URL, jobsHandler(map[string]string{
+		"8.8.8.8": `{"count":1,"total_pages":1,"results":[{"id":1,"observable_name":"8.8.8.8","received_request_time":"2023-05-03T10:00:00Z"}]}`,
+	}))
+	multiClient, err := gothreatmatrix.NewMultiClient([]gothreatmatrix.Instance{
+		newTestInstance(t, "eu", euHandler),
+		newTestInstance(t, "us", usHandler),
+		newDownInstance(t, "lab"),
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	jobs, err := multiClient.SearchJobs(context.Background(), "8.8.8.8")
+	fanOutError := &gothreatmatrix.FanOutError{}
+	if !errors.As(err, &fanOutError) {
+		t.Fatalf("expected a FanOutError, got: %v", err)
+	}
+	failed := []string{}
+	for name := range fanOutError.Errors {
+		failed = append(failed, name)
+	}
+	testWantData(t, []string{"lab"}, failed)
+	found := []string{}
+	for _, job := range jobs {
+		found = append(found, fmt.Sprintf("%s %d", job.Instance, job.ID))
+	}
+	testWantData(t, []string{"us 1", "eu 1"}, found)
+	// * a single failure does not mark an instance down with the default threshold
+	testWantData(t, 1, multiClient.Health()[2].ConsecutiveFailures)
+	testWantData(t, true, multiClient.Health()[2].Healthy)
+}
+
+func TestMultiClientFanOutCancelled(t *testing.T) {
+	multiClient, err := gothreatmatrix.NewMultiClient([]gothreatmatrix.Instance{
+		newTestInstance(t, "eu", http.NewServeMux()),
+		newTestInstance(t, "us", http.NewServeMux()),
+		newTestInstance(t, "lab", http.NewServeMux()),
+	}, &gothreatmatrix.MultiClientOptions{Concurrency: 1})
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var calls int32
+	err = multiClient.FanOut(ctx, func(ctx context.Context, instance gothreatmatrix.Instance) error {
+		atomic.AddInt32(&calls, 1)
+		// * cancelled while the only slot is taken so no other read may start
+		cancel()
+		return nil
+	})
+	testWantData(t, int32(1), atomic.LoadInt32(&calls))
+	fanOutError := &gothreatmatrix.FanOutError{}
+	if !errors.As(err, &fanOutError) {
+		t.Fatalf("expected a FanOutError, got: %v", err)
+	}
+	skipped := []string{}
+	for _, name := range []string{"eu", "us", "lab"} {
+		if fanOutError.Errors[name] == context.Canceled {
+			skipped = append(skipped, name)
+		}
+	}
+	testWantData(t, []string{"us", "lab"}, skipped)
+	// * the skipped instances were not called so their health is unknown
+	testWantData(t, true, multiClient.Health()[1].LastCheckedAt.IsZero())
+}
@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/khulnasoft/go-threatmatrix/constants"
+	"github.com/khulnasoft/go-threatmatrix/gothreatmatrix"
+)
+
+const shodanAnalyzerJson = `{"name":"Shodan_Search","secrets":{"api_key_name":{"env_var_key":"SHODAN_KEY","required":true}},"params":{"shodan_analysis":{"value":"search","type":"str"},"minify":{"value":false,"type":"bool"}},"verification":{"configured":true,"missing_secrets":[]}}`
+
+const shodanPluginConfigsJson = `[{"id":7,"attribute":"shodan_analysis","value":"host","type":"str","is_secret":false,"for_organization":true,"organization":"StrawHats","owner":"luffy","analyzer_config":"Shodan_Search"},{"id":8,"attribute":"api_key_name","value":"s3cr3t-k3y","type":"str","is_secret":true,"for_organization":true,"organization":"StrawHats","owner":"luffy","analyzer_config":"Shodan_Search"},{"id":9,"attribute":"minify","value":true,"type":"bool","is_secret":false,"for_organization":false,"owner":"zoro","analyzer_config":"Shodan_Search"},{"id":10,"attribute":"shodan_analysis","value":"host","type":"str","is_secret":false,"for_organization":true,"organization":"StrawHats","owner":"luffy","analyzer_config":"Shodan_Ping"}]`
+
+func TestOrganizationServicePluginValues(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input: gothreatmatrix.PluginTypeAnalyzer,
+		Want: []string{
+			"api_key_name secret overridden(8) ********",
+			"minify inherited false",
+			"shodan_analysis overridden(7) host",
+		},
+	}
+	testCases["unsupportedType"] = TestData{
+		Input: "visualizer",
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			apiHandler.HandleFunc(fmt.Sprintf(constants.SPECIFIC_ANALYZER_CONFIG_URL, "Shodan_Search"), func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "GET")
+				w.Write([]byte(shodanAnalyzerJson))
+			})
+			apiHandler.HandleFunc(constants.PLUGIN_CONFIG_URL, func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "GET")
+				w.Write([]byte(shodanPluginConfigsJson))
+			})
+			pluginValues, err := client.OrganizationService.PluginValues(ctx, testCase.Input.(string), "Shodan_Search")
+			if testCase.Want == nil {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			summaries := []string{}
+			for _, pluginValue := range pluginValues {
+				summary := pluginValue.Attribute
+				if pluginValue.IsSecret {
+					summary += " secret"
+				}
+				if pluginValue.Overridden {
+					summary += fmt.Sprintf(" overridden(%d)", pluginValue.ConfigID)
+				} else {
+					summary += " inherited"
+				}
+				summaries = append(summaries, fmt.Sprintf("%s %v", summary, pluginValue.Value))
+			}
+			testWantData(t, testCase.Want, summaries)
+		})
+	}
+}
+
+func TestOrganizationServiceSetParams(t *testing.T) {
+	client, apiHandler, closeServer := setup()
+	defer closeServer()
+	ctx := context.Background()
+	apiHandler.HandleFunc(fmt.Sprintf(constants.SPECIFIC_ANALYZER_CONFIG_URL, "Shodan_Search"), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		w.Write([]byte(shodanAnalyzerJson))
+	})
+	// * the requests are recorded then checked from the test goroutine
+	requests := []string{}
+	recordRequest := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Write([]byte(shodanPluginConfigsJson))
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("Error: %s", err)
+		}
+		requests = append(requests, fmt.Sprintf("%s %s %s", r.Method, r.URL.Path, body))
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}
+	apiHandler.HandleFunc(constants.PLUGIN_CONFIG_URL, recordRequest)
+	apiHandler.HandleFunc(constants.PLUGIN_CONFIG_URL+"/", recordRequest)
+
+	// * the organization already overrides shodan_analysis so only minify is created
+	pluginValues, err := client.OrganizationService.SetParams(ctx, gothreatmatrix.PluginTypeAnalyzer, "Shodan_Search", map[string]interface{}{"shodan_analysis": "scan", "minify": true})
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	testWantData(t, []string{
+		`PATCH /api/plugin-config/7 {"value":"scan"}`,
+		`POST /api/plugin-config [{"analyzer_config":"Shodan_Search","attribute":"minify","for_organization":true,"value":true}]`,
+	}, requests)
+	testWantData(t, 3, len(pluginValues))
+
+	requests = []string{}
+	if _, err := client.OrganizationService.SetSecrets(ctx, gothreatmatrix.PluginTypeAnalyzer, "Shodan_Search", map[string]gothreatmatrix.SecretValue{"api_key_name": "n3w-k3y"}); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	testWantData(t, []string{`PATCH /api/plugin-config/8 {"value":"n3w-k3y"}`}, requests)
+
+	// * undeclared params are rejected before anything is sent
+	requests = []string{}
+	if _, err := client.OrganizationService.SetParams(ctx, gothreatmatrix.PluginTypeAnalyzer, "Shodan_Search", map[string]interface{}{"shodan": "host"}); err == nil {
+		t.Fatalf("expected an error")
+	}
+	testWantData(t, []string{}, requests)
+}
+
+func TestOrganizationServiceUnset(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["overridden"] = TestData{
+		Input:      "shodan_analysis",
+		StatusCode: http.StatusNoContent,
+		Want:       true,
+	}
+	testCases["inherited"] = TestData{
+		Input: "minify",
+		Want:  false,
+	}
+	// * an override without id must not be deleted through /api/plugin-config/0
+	testCases["withoutId"] = TestData{
+		Input: "shodan_analysis",
+		Data:  `[{"attribute":"shodan_analysis","value":"host","type":"str","is_secret":false,"for_organization":true,"organization":"StrawHats","owner":"luffy","analyzer_config":"Shodan_Search"}]`,
+		Want:  false,
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			apiHandler.HandleFunc(constants.PLUGIN_CONFIG_URL, func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "GET")
+				if testCase.Data != "" {
+					w.Write([]byte(testCase.Data))
+					return
+				}
+				w.Write([]byte(shodanPluginConfigsJson))
+			})
+			deleted := false
+			for _, id := range []int{0, 7} {
+				apiHandler.HandleFunc(fmt.Sprintf(constants.SPECIFIC_PLUGIN_CONFIG_URL, id), func(w http.ResponseWriter, r *http.Request) {
+					testMethod(t, r, "DELETE")
+					deleted = true
+					w.WriteHeader(testCase.StatusCode)
+				})
+			}
+			unset, err := client.OrganizationService.Unset(ctx, gothreatmatrix.PluginTypeAnalyzer, "Shodan_Search", testCase.Input.(string))
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			testWantData(t, testCase.Want, unset)
+			testWantData(t, testCase.Want, deleted)
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/khulnasoft/go-threatmatrix/constants"
+	"github.com/khulnasoft/go-threatmatrix/gothreatmatrix"
+)
+
+func TestPivotServiceListAll(t *testing.T) {
+	pages := map[string]string{
+		"1": `{"count":3,"total_pages":2,"results":[{"name":"DomainToIp","disabled":false,"related_analyzer_configs":["Classic_DNS"],"playbooks_choice":["Ip_Reputation"]},{"name":"HashToFile","disabled":true}]}`,
+		"2": `{"count":3,"total_pages":2,"results":[{"name":"UrlToDomain","disabled":false}]}`,
+	}
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Want: []string{"DomainToIp", "HashToFile", "UrlToDomain"},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			apiHandler.HandleFunc(constants.PIVOT_CONFIG_URL, func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "GET")
+				w.Write([]byte(pages[r.URL.Query().Get("page")]))
+			})
+			pivots, err := client.PivotService.ListAll(ctx)
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			pivotNames := []string{}
+			for _, pivot := range pivots {
+				pivotNames = append(pivotNames, pivot.Name)
+			}
+			testWantData(t, testCase.Want, pivotNames)
+			testWantData(t, []string{"Ip_Reputation"}, pivots[0].PlaybooksChoice)
+		})
+	}
+}
+
+func TestPivotServiceGet(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input:      "DomainToIp",
+		Data:       `{"name":"DomainToIp","python_module":"compare.Compare","disabled":false,"related_analyzer_configs":["Classic_DNS"]}`,
+		StatusCode: http.StatusOK,
+		Want:       []string{"Classic_DNS"},
+	}
+	testCases["pivotDoesntExist"] = TestData{
+		Input:      "notAPivot",
+		Data:       `{"detail": "Not found."}`,
+		StatusCode: http.StatusNotFound,
+		Want: &gothreatmatrix.Error{
+			StatusCode: http.StatusNotFound,
+			Message:    `{"detail": "Not found."}`,
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			input := testCase.Input.(string)
+			apiHandler.Handle(fmt.Sprintf(constants.SPECIFIC_PIVOT_CONFIG_URL, input), serverHandler(t, testCase, "GET"))
+			pivotConfig, err := client.PivotService.Get(ctx, input)
+			if err != nil {
+				testError(t, testCase, err)
+			} else {
+				testWantData(t, testCase.Want, pivotConfig.RelatedAnalyzerConfigs)
+			}
+		})
+	}
+}
+
+func TestPivotServiceHealthCheck(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input:      "DomainToIp",
+		Data:       `{"status": true}`,
+		StatusCode: http.StatusOK,
+		Want:       true,
+	}
+	testCases["pivotDoesntExist"] = TestData{
+		Input:      "notAPivot",
+		Data:       `{"errors": {"detail": "Pivot doesn't exist"}}`,
+		StatusCode: http.StatusBadRequest,
+		Want: &gothreatmatrix.Error{
+			StatusCode: http.StatusBadRequest,
+			Message:    `{"errors": {"detail": "Pivot doesn't exist"}}`,
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			input := testCase.Input.(string)
+			apiHandler.Handle(fmt.Sprintf(constants.PIVOT_HEALTHCHECK_URL, input), serverHandler(t, testCase, "GET"))
+			status, err := client.PivotService.HealthCheck(ctx, input)
+			if err != nil {
+				testError(t, testCase, err)
+			} else {
+				testWantData(t, testCase.Want, status)
+			}
+		})
+	}
+}
+
+func TestPivotServiceEnable(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input: "HashToFile",
+		Want:  false,
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			input := testCase.Input.(string)
+			body := map[string]interface{}{}
+			apiHandler.HandleFunc(fmt.Sprintf(constants.SPECIFIC_PIVOT_CONFIG_URL, input), func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "PATCH")
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("Error: %s", err)
+				}
+				w.Write([]byte(`{"name":"HashToFile","disabled":false}`))
+			})
+			pivotConfig, err := client.PivotService.Enable(ctx, input)
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			testWantData(t, testCase.Want, pivotConfig.Disabled)
+			testWantData(t, map[string]interface{}{"disabled": false}, body)
+		})
+	}
+}
+
+// flattenPivotTree describes every node as "depth:jobID:pivot", appending "+" to truncated nodes.
+func flattenPivotTree(tree *gothreatmatrix.PivotTree, depth int) []string {
+	node := fmt.Sprintf("%d:%d:%s", depth, tree.Job.ID, tree.Pivot)
+	if tree.Truncated {
+		node += "+"
+	}
+	nodes := []string{node}
+	for index := range tree.Children {
+		nodes = append(nodes, flattenPivotTree(&tree.Children[index], depth+1)...)
+	}
+	return nodes
+}
+
+func TestPivotServiceInvestigationTree(t *testing.T) {
+	// * job 1 pivots into 2 and 3, job 3 pivots into 4 and back into 1
+	pivotMaps := map[string]string{
+		"1": `{"count":2,"total_pages":1,"results":[{"id":2,"starting_job":1,"pivot_config":"UrlToDomain","ending_job":3},{"id":1,"starting_job":1,"pivot_config":"DomainToIp","ending_job":2}]}`,
+		"2": `{"count":0,"total_pages":1,"results":[]}`,
+		"3": `{"count":2,"total_pages":1,"results":[{"id":3,"starting_job":3,"pivot_config":"DomainToIp","ending_job":4},{"id":4,"starting_job":3,"pivot_config":"Loop","ending_job":1}]}`,
+		"4": `{"count":0,"total_pages":1,"results":[]}`,
+	}
+	testCases := make(map[string]TestData)
+	testCases["full"] = TestData{
+		Input: 0,
+		Want:  []string{"0:1:", "1:2:DomainToIp", "1:3:UrlToDomain", "2:1:Loop", "2:4:DomainToIp"},
+	}
+	testCases["depthLimited"] = TestData{
+		Input: 1,
+		Want:  []string{"0:1:", "1:2:DomainToIp", "1:3:UrlToDomain+"},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			apiHandler.HandleFunc(constants.BASE_JOB_URL+"/", func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "GET")
+				jobId := strings.TrimPrefix(r.URL.Path, constants.BASE_JOB_URL+"/")
+				w.Write([]byte(fmt.Sprintf(`{"id":%s,"status":"reported_without_fails"}`, jobId)))
+			})
+			apiHandler.HandleFunc(constants.PIVOT_MAP_URL, func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "GET")
+				w.Write([]byte(pivotMaps[r.URL.Query().Get("starting_job")]))
+			})
+			tree, err := client.PivotService.InvestigationTree(ctx, 1, testCase.Input.(int))
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			testWantData(t, testCase.Want, flattenPivotTree(tree, 0))
+		})
+	}
+}
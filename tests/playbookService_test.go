@@ -5,6 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/khulnasoft/go-threatmatrix/constants"
@@ -180,3 +184,460 @@ func TestGetPlaybookByName(t *testing.T) {
 		})
 	}
 }
+
+// pluginConfigHandlers mocks the analyzer, connector and pivot configurations used to validate playbooks,
+// it returns the number of configuration requests received.
+func pluginConfigHandlers(apiHandler *http.ServeMux) *int32 {
+	var configRequests int32
+	apiHandler.HandleFunc(constants.ANALYZER_CONFIG_URL, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&configRequests, 1)
+		w.Write([]byte(`{"Classic_DNS":{"name":"Classic_DNS"},"Google_DNS":{"name":"Google_DNS"}}`))
+	})
+	apiHandler.HandleFunc(constants.CONNECTOR_CONFIG_URL, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&configRequests, 1)
+		w.Write([]byte(`{"YETI":{"name":"YETI"}}`))
+	})
+	apiHandler.HandleFunc(constants.PIVOT_CONFIG_URL, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&configRequests, 1)
+		w.Write([]byte(`{"count":1,"total_pages":1,"results":[{"name":"DomainToIp"}]}`))
+	})
+	return &configRequests
+}
+
+func TestPlaybookServiceCreate(t *testing.T) {
+	playbookConfigJson := `{"id":12,"name":"Custom_DNS","description":"resolve domains","type":["domain"],"analyzers":["Classic_DNS","Google_DNS"],"connectors":["YETI"],"pivots":["DomainToIp"],"runtime_configuration":{},"scan_mode":2,"scan_check_time":"1:00:00:00","tags":[],"tlp":"AMBER","starting":true,"owner":"hussain","disabled":false}`
+	playbookConfig := gothreatmatrix.PlaybookConfig{}
+	if unmarshalError := json.Unmarshal([]byte(playbookConfigJson), &playbookConfig); unmarshalError != nil {
+		t.Fatalf("Error: %s", unmarshalError)
+	}
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input:      playbookConfig,
+		Data:       playbookConfigJson,
+		StatusCode: http.StatusCreated,
+		Want:       &playbookConfig,
+	}
+	unknownPlaybookConfig := playbookConfig
+	unknownPlaybookConfig.Analyzers = []string{"Classic_DNS", "Not_An_Analyzer"}
+	unknownPlaybookConfig.Pivots = []string{"NotAPivot"}
+	testCases["unknownPlugins"] = TestData{
+		Input: unknownPlaybookConfig,
+		Want: &gothreatmatrix.PlaybookValidationError{
+			UnknownAnalyzers: []string{"Not_An_Analyzer"},
+			UnknownPivots:    []string{"NotAPivot"},
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			pluginConfigHandlers(apiHandler)
+			fields := map[string]interface{}{}
+			apiHandler.HandleFunc(constants.BASE_PLAYBOOK_URL, func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "POST")
+				if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+					t.Errorf("Error: %s", err)
+				}
+				w.WriteHeader(testCase.StatusCode)
+				w.Write([]byte(testCase.Data))
+			})
+			playbook, ok := testCase.Input.(gothreatmatrix.PlaybookConfig)
+			if ok {
+				gottenPlaybook, err := client.PlaybookService.Create(ctx, &playbook)
+				if err != nil {
+					testWantData(t, testCase.Want, err)
+				} else {
+					testWantData(t, testCase.Want, gottenPlaybook)
+				}
+				if _, ok := fields["id"]; ok {
+					t.Fatalf("The playbook ID should not be sent")
+				}
+			}
+		})
+	}
+}
+
+func TestPlaybookServiceUpdate(t *testing.T) {
+	playbookConfigJson := `{"id":12,"name":"Custom_DNS","description":"resolve domains","type":["domain"],"analyzers":["Classic_DNS"],"connectors":[],"pivots":[],"runtime_configuration":{},"scan_mode":2,"scan_check_time":"1:00:00:00","tags":[],"tlp":"RED","starting":true,"owner":"hussain","disabled":false}`
+	playbookConfig := gothreatmatrix.PlaybookConfig{}
+	if unmarshalError := json.Unmarshal([]byte(playbookConfigJson), &playbookConfig); unmarshalError != nil {
+		t.Fatalf("Error: %s", unmarshalError)
+	}
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input:      playbookConfig,
+		Data:       playbookConfigJson,
+		StatusCode: http.StatusOK,
+		Want:       &playbookConfig,
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			pluginConfigHandlers(apiHandler)
+			apiHandler.Handle(fmt.Sprintf(constants.SPECIFIC_PLAYBOOK_URL, "Custom_DNS"), serverHandler(t, testCase, "PATCH"))
+			playbook, ok := testCase.Input.(gothreatmatrix.PlaybookConfig)
+			if ok {
+				gottenPlaybook, err := client.PlaybookService.Update(ctx, playbook.Name, &playbook)
+				if err != nil {
+					testError(t, testCase, err)
+				} else {
+					testWantData(t, testCase.Want, gottenPlaybook)
+				}
+			}
+		})
+	}
+}
+
+func TestPlaybookServiceDisable(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["enabled"] = TestData{
+		Input: `{"name":"Dns","disabled":false,"starting":true}`,
+		Want:  []string{"GET", "PATCH"},
+	}
+	testCases["alreadyDisabled"] = TestData{
+		Input: `{"name":"Dns","disabled":true,"starting":true}`,
+		Want:  []string{"GET"},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			methods := []string{}
+			fields := map[string]interface{}{}
+			apiHandler.HandleFunc(fmt.Sprintf(constants.SPECIFIC_PLAYBOOK_URL, "Dns"), func(w http.ResponseWriter, r *http.Request) {
+				methods = append(methods, r.Method)
+				if r.Method == http.MethodPatch {
+					if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+						t.Errorf("Error: %s", err)
+					}
+					w.Write([]byte(`{"name":"Dns","disabled":true,"starting":true}`))
+					return
+				}
+				w.Write([]byte(testCase.Input.(string)))
+			})
+			gottenPlaybook, err := client.PlaybookService.Disable(ctx, "Dns")
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			testWantData(t, true, gottenPlaybook.Disabled)
+			testWantData(t, true, gottenPlaybook.Starting)
+			testWantData(t, testCase.Want, methods)
+			if len(methods) > 1 {
+				testWantData(t, map[string]interface{}{"disabled": true}, fields)
+			}
+		})
+	}
+}
+
+func TestPlaybookServiceDelete(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input:      "Custom_DNS",
+		Data:       "",
+		StatusCode: http.StatusNoContent,
+		Want:       true,
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			playbookName, ok := testCase.Input.(string)
+			if ok {
+				apiHandler.Handle(fmt.Sprintf(constants.SPECIFIC_PLAYBOOK_URL, playbookName), serverHandler(t, testCase, "DELETE"))
+				isDeleted, err := client.PlaybookService.Delete(ctx, playbookName)
+				if err != nil {
+					testError(t, testCase, err)
+				} else {
+					testWantData(t, testCase.Want, isDeleted)
+				}
+			}
+		})
+	}
+}
+
+func TestPlaybookServiceSync(t *testing.T) {
+	playbookListJson := `{"count":3,"total_pages":1,"results":[
+		{"id":1,"name":"Dns","description":"dns","type":["domain"],"analyzers":["Classic_DNS","Google_DNS"],"connectors":[],"pivots":[],"runtime_configuration":{},"scan_mode":2,"tags":[],"tlp":"AMBER","starting":true},
+		{"id":2,"name":"Unchanged","description":"same","type":["ip"],"analyzers":["Classic_DNS"],"connectors":["YETI"],"pivots":[],"runtime_configuration":{"analyzers":{"Classic_DNS":{"query_type":"A"}}},"scan_mode":2,"tags":["a"],"tlp":"CLEAR"},
+		{"id":3,"name":"Stale","description":"old","type":["ip"],"analyzers":[],"connectors":[],"pivots":[],"runtime_configuration":{},"scan_mode":1,"tags":[],"tlp":"RED"}
+	]}`
+	playbookFiles := map[string]string{
+		"dns.yaml": `name: Dns
+description: dns
+type: [domain]
+analyzers: [Google_DNS, Classic_DNS]
+pivots: [DomainToIp]
+scan_mode: 1
+tlp: amber
+`,
+		"unchanged.json": `{"name":"Unchanged","description":"same","type":["ip"],"analyzers":["Classic_DNS"],"connectors":["YETI"],"runtime_configuration":{"analyzers":{"Classic_DNS":{"query_type":"A"}}},"scan_mode":2,"tags":["a"],"tlp":"CLEAR"}`,
+		"new.yml": `name: New
+description: brand new
+type: [url]
+analyzers: [Classic_DNS]
+connectors: [YETI]
+pivots: [DomainToIp]
+tlp: WHITE
+`,
+		"README.md": "not a playbook",
+	}
+	testCases := make(map[string]TestData)
+	testCases["dryRun"] = TestData{
+		Input: gothreatmatrix.PlaybookSyncOptions{DryRun: true, Prune: true},
+		Want:  []string{},
+	}
+	testCases["apply"] = TestData{
+		Input: gothreatmatrix.PlaybookSyncOptions{Prune: true},
+		Want:  []string{"POST /api/playbook", "PATCH /api/playbook/Dns", "DELETE /api/playbook/Stale"},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			dir := t.TempDir()
+			for fileName, content := range playbookFiles {
+				if err := os.WriteFile(path.Join(dir, fileName), []byte(content), 0600); err != nil {
+					t.Fatalf("Error: %s", err)
+				}
+			}
+			configRequests := pluginConfigHandlers(apiHandler)
+			requests := []string{}
+			recordRequest := func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					w.Write([]byte(playbookListJson))
+					return
+				}
+				requests = append(requests, r.Method+" "+r.URL.Path)
+				if r.Method == http.MethodDelete {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				w.Write([]byte(`{}`))
+			}
+			apiHandler.HandleFunc(constants.BASE_PLAYBOOK_URL, recordRequest)
+			apiHandler.HandleFunc(constants.BASE_PLAYBOOK_URL+"/", recordRequest)
+			options, ok := testCase.Input.(gothreatmatrix.PlaybookSyncOptions)
+			if ok {
+				plan, err := client.PlaybookService.Sync(ctx, dir, &options)
+				if err != nil {
+					t.Fatalf("Error: %s", err)
+				}
+				testWantData(t, "+ create New\n~ update Dns (pivots, scan_mode, starting)\n- delete Stale", plan.String())
+				// * the analyzer, connector and pivot configurations are fetched once for the whole run
+				testWantData(t, int32(3), atomic.LoadInt32(configRequests))
+				testWantData(t, !options.DryRun, plan.Applied)
+				testWantData(t, testCase.Want, requests)
+			}
+		})
+	}
+}
+
+func TestPlaybookServiceExportPlaybooks(t *testing.T) {
+	playbookListJson := `{"count":1,"total_pages":1,"results":[{"id":1,"name":"Dns","description":"dns","type":["domain"],"analyzers":["Classic_DNS"],"connectors":[],"pivots":[],"runtime_configuration":{"analyzers":{"Classic_DNS":{"query_type":"A"}}},"scan_mode":2,"scan_check_time":"1:00:00:00","tags":[],"tlp":"AMBER","starting":true,"owner":"hussain","disabled":false}]}`
+	testCases := make(map[string]TestData)
+	testCases["yaml"] = TestData{
+		Input: gothreatmatrix.PlaybookFormatYAML,
+	}
+	testCases["json"] = TestData{
+		Input: gothreatmatrix.PlaybookFormatJSON,
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			apiHandler.Handle(constants.BASE_PLAYBOOK_URL, serverHandler(t, TestData{StatusCode: http.StatusOK, Data: playbookListJson}, "GET"))
+			format, ok := testCase.Input.(string)
+			if ok {
+				dir := t.TempDir()
+				filePaths, err := client.PlaybookService.ExportPlaybooks(ctx, dir, format)
+				if err != nil {
+					t.Fatalf("Error: %s", err)
+				}
+				testWantData(t, []string{path.Join(dir, "Dns."+format)}, filePaths)
+				// * the exported files must load back to the same playbooks, without the server side fields
+				loadedPlaybooks, err := gothreatmatrix.LoadPlaybookFiles(dir)
+				if err != nil {
+					t.Fatalf("Error: %s", err)
+				}
+				playbookList := gothreatmatrix.PlaybookListResponse{}
+				if unmarshalError := json.Unmarshal([]byte(playbookListJson), &playbookList); unmarshalError != nil {
+					t.Fatalf("Error: %s", unmarshalError)
+				}
+				wantPlaybook := playbookList.Results[0]
+				wantPlaybook.ID = 0
+				wantPlaybook.Owner = ""
+				testWantData(t, []gothreatmatrix.PlaybookConfig{wantPlaybook}, loadedPlaybooks)
+			}
+		})
+	}
+}
+
+func TestClassifyObservable(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["ipv4"] = TestData{Input: "8.8.8.8", Want: gothreatmatrix.IPClassification}
+	testCases["ipv6"] = TestData{Input: "2001:4860:4860::8888", Want: gothreatmatrix.IPClassification}
+	testCases["url"] = TestData{Input: "https://google.com/search?q=a", Want: gothreatmatrix.URLClassification}
+	testCases["domain"] = TestData{Input: " dns.google.com ", Want: gothreatmatrix.DomainClassification}
+	testCases["md5"] = TestData{Input: "44d88612fea8a8f36de82e1278abb02f", Want: gothreatmatrix.HashClassification}
+	testCases["sha256"] = TestData{Input: "275a021bbfb6489e54d471899f7db9d1663fc695ec2fe2a2c4538aabf651fd0f", Want: gothreatmatrix.HashClassification}
+	testCases["generic"] = TestData{Input: "john.doe@", Want: gothreatmatrix.GenericClassification}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			testWantData(t, testCase.Want, gothreatmatrix.ClassifyObservable(testCase.Input.(string)))
+		})
+	}
+}
+
+func TestAnalyzeWithBestPlaybook(t *testing.T) {
+	playbookListJson := `{"count":4,"total_pages":1,"results":[
+		{"id":1,"name":"Dns","type":["domain"],"starting":true,"disabled":false},
+		{"id":2,"name":"Ip_Disabled","type":["ip"],"starting":true,"disabled":true},
+		{"id":3,"name":"Ip_Reputation","type":["ip","domain"],"starting":true,"disabled":false},
+		{"id":4,"name":"Ip_Pivoted","type":["ip"],"starting":false,"disabled":false}
+	]}`
+	analysisJson := `{"count":1,"results":[{"job_id":7,"status":"accepted","warnings":[],"analyzers_running":["Classic_DNS"],"connectors_running":[]}]}`
+	testCases := make(map[string]TestData)
+	testCases["preferred"] = TestData{
+		Input: []interface{}{"8.8.8.8", []string{"Ip_Disabled", "Ip_Pivoted", "Ip_Reputation"}},
+		Want: &gothreatmatrix.PlaybookSelection{
+			Classification: gothreatmatrix.IPClassification,
+			Reasons: []string{
+				"skipped Ip_Disabled: disabled",
+				"skipped Ip_Pivoted: not a starting playbook",
+				"picked Ip_Reputation: supports ip and is preference #3",
+			},
+		},
+	}
+	testCases["alphabetical"] = TestData{
+		Input: []interface{}{"dns.google.com", []string{}},
+		Want: &gothreatmatrix.PlaybookSelection{
+			Classification: gothreatmatrix.DomainClassification,
+			Reasons:        []string{"picked Dns: supports domain and no preferred playbook matched"},
+		},
+	}
+	testCases["noMatch"] = TestData{
+		Input: []interface{}{"https://google.com", []string{"Dns"}},
+		Want: &gothreatmatrix.PlaybookSelection{
+			Classification: gothreatmatrix.URLClassification,
+			Reasons: []string{
+				"skipped Dns: does not support url",
+				"skipped Ip_Disabled: disabled",
+				"skipped Ip_Pivoted: not a starting playbook",
+				"skipped Ip_Reputation: does not support url",
+			},
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			listCalls := 0
+			apiHandler.HandleFunc(constants.BASE_PLAYBOOK_URL, func(w http.ResponseWriter, r *http.Request) {
+				listCalls++
+				w.Write([]byte(playbookListJson))
+			})
+			submitted := []string{}
+			apiHandler.HandleFunc(constants.ANALYZE_OBSERVABLE_PLAYBOOK_URL, func(w http.ResponseWriter, r *http.Request) {
+				body := map[string]interface{}{}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("Error: %s", err)
+				}
+				submitted = append(submitted, fmt.Sprint(body["playbook_requested"]))
+				w.Write([]byte(analysisJson))
+			})
+			input := testCase.Input.([]interface{})
+			want := testCase.Want.(*gothreatmatrix.PlaybookSelection)
+			params := &gothreatmatrix.PlaybookSelectionParams{Preference: input[1].([]string)}
+			for i := 0; i < 2; i++ {
+				selection, analysisResponse, err := client.AnalyzeWithBestPlaybook(ctx, input[0].(string), params)
+				testWantData(t, want.Classification, selection.Classification)
+				testWantData(t, want.Reasons, selection.Reasons)
+				if name == "noMatch" {
+					if err == nil {
+						t.Fatalf("expected an error")
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("Error: %s", err)
+				}
+				testWantData(t, 7, analysisResponse.Results[0].JobID)
+			}
+			// * the playbook list is cached between the two submissions
+			testWantData(t, 1, listCalls)
+			if name != "noMatch" {
+				picked := want.Reasons[len(want.Reasons)-1]
+				testWantData(t, true, len(submitted) == 2 && strings.HasPrefix(picked, "picked "+submitted[0]+":"))
+			}
+		})
+	}
+}
+
+func TestSelectPlaybookAfterDisable(t *testing.T) {
+	client, apiHandler, closeServer := setup()
+	defer closeServer()
+	ctx := context.Background()
+	var dnsDisabled int32
+	playbookJson := func() string {
+		return fmt.Sprintf(`{"id":1,"name":"Dns","type":["domain"],"starting":true,"disabled":%t}`, atomic.LoadInt32(&dnsDisabled) == 1)
+	}
+	apiHandler.HandleFunc(constants.BASE_PLAYBOOK_URL, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(fmt.Sprintf(`{"count":2,"total_pages":1,"results":[%s,{"id":2,"name":"Dns_Fallback","type":["domain"],"starting":true,"disabled":false}]}`, playbookJson())))
+	})
+	apiHandler.HandleFunc(fmt.Sprintf(constants.SPECIFIC_PLAYBOOK_URL, "Dns"), func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			atomic.StoreInt32(&dnsDisabled, 1)
+		}
+		w.Write([]byte(playbookJson()))
+	})
+	picked := []string{}
+	for i := 0; i < 2; i++ {
+		selection, err := client.PlaybookService.SelectPlaybook(ctx, gothreatmatrix.DomainClassification, nil)
+		if err != nil {
+			t.Fatalf("Error: %s", err)
+		}
+		picked = append(picked, selection.Playbook.Name)
+		if i == 0 {
+			// * disabling drops the cached list so the next selection sees it
+			if _, err := client.PlaybookService.Disable(ctx, "Dns"); err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+		}
+	}
+	testWantData(t, []string{"Dns", "Dns_Fallback"}, picked)
+}
+
+func TestCachedPlaybooksCopy(t *testing.T) {
+	client, apiHandler, closeServer := setup()
+	defer closeServer()
+	ctx := context.Background()
+	apiHandler.HandleFunc(constants.BASE_PLAYBOOK_URL, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"count":1,"total_pages":1,"results":[{"id":1,"name":"Dns","type":["domain"],"analyzers":["Classic_DNS"],"runtime_configuration":{"analyzers":{"Classic_DNS":{"query_type":"A"}}},"starting":true,"disabled":false}]}`))
+	})
+	for i := 0; i < 2; i++ {
+		playbooks, err := client.PlaybookService.CachedPlaybooks(ctx)
+		if err != nil {
+			t.Fatalf("Error: %s", err)
+		}
+		testWantData(t, []string{"domain"}, playbooks[0].Type)
+		testWantData(t, []string{"Classic_DNS"}, playbooks[0].Analyzers)
+		testWantData(t, map[string]interface{}{"Classic_DNS": map[string]interface{}{"query_type": "A"}}, playbooks[0].RuntimeConfiguration["analyzers"])
+		// * editing the returned playbooks leaves the cache untouched
+		playbooks[0].Type[0] = "ip"
+		playbooks[0].Analyzers = append(playbooks[0].Analyzers[:0], "Shodan_Search")
+		playbooks[0].RuntimeConfiguration["analyzers"].(map[string]interface{})["Classic_DNS"].(map[string]interface{})["query_type"] = "MX"
+	}
+	selection, err := client.PlaybookService.SelectPlaybook(ctx, gothreatmatrix.DomainClassification, nil)
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	testWantData(t, "Dns", selection.Playbook.Name)
+}
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/khulnasoft/go-threatmatrix/constants"
+	"github.com/khulnasoft/go-threatmatrix/gothreatmatrix"
+)
+
+func TestQuotaGuard(t *testing.T) {
+	client, apiHandler, closeServer := setup()
+	defer closeServer()
+	ctx := context.Background()
+	logs := &bytes.Buffer{}
+	client.Logger.Logger.SetOutput(logs)
+
+	accessCalls := 0
+	apiHandler.HandleFunc(constants.USER_DETAILS_URL, func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		accessCalls++
+		w.Write([]byte(`{"user":{"username":"hussain"},"access":{"total_submissions":38,"month_submissions":8}}`))
+	})
+	submissions := 0
+	apiHandler.HandleFunc(constants.ANALYZE_OBSERVABLE_URL, func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		submissions++
+		if submissions == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors":{"observable_name":"invalid"}}`))
+			return
+		}
+		w.Write([]byte(`{"job_id":260,"status":"accepted"}`))
+	})
+	multipleSubmissions := 0
+	apiHandler.HandleFunc(constants.ANALYZE_MULTIPLE_OBSERVABLES_URL, func(w http.ResponseWriter, r *http.Request) {
+		multipleSubmissions++
+	})
+
+	if err := client.QuotaGuard.Enable(gothreatmatrix.QuotaGuardOptions{}); err == nil {
+		t.Fatalf("expected an error")
+	}
+	if err := client.QuotaGuard.Enable(gothreatmatrix.QuotaGuardOptions{MonthlyBudget: 10}); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	observableParams := gothreatmatrix.ObservableAnalysisParams{ObservableName: "192.168.69.42"}
+
+	// * a refused submission does not count against the budget
+	if _, err := client.CreateObservableAnalysis(ctx, &observableParams); err == nil {
+		t.Fatalf("expected an error")
+	}
+	usage, err := client.QuotaGuard.Usage(ctx)
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	testWantData(t, 2, usage.Remaining)
+	if strings.Contains(logs.String(), "almost reached") {
+		t.Fatalf("unexpected warning: %s", logs.String())
+	}
+
+	// * the 9th submission reaches 90% of the budget
+	if _, err := client.CreateObservableAnalysis(ctx, &observableParams); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if !strings.Contains(logs.String(), "Monthly submission budget almost reached") {
+		t.Fatalf("expected a warning, got: %s", logs.String())
+	}
+
+	multipleParams := gothreatmatrix.MultipleObservableAnalysisParams{
+		Observables: [][]string{{"ip", "1.1.1.1"}, {"ip", "8.8.8.8"}},
+	}
+	_, err = client.CreateMultipleObservableAnalysis(ctx, &multipleParams)
+	if !errors.Is(err, gothreatmatrix.ErrQuotaExceeded) {
+		t.Fatalf("expected ErrQuotaExceeded, got: %v", err)
+	}
+	quotaError := &gothreatmatrix.QuotaExceededError{}
+	if !errors.As(err, &quotaError) {
+		t.Fatalf("expected a QuotaExceededError, got: %T", err)
+	}
+	testWantData(t, &gothreatmatrix.QuotaExceededError{MonthlyBudget: 10, MonthSubmissions: 9, Requested: 2}, quotaError)
+	testWantData(t, 0, multipleSubmissions)
+	testWantData(t, 1, accessCalls)
+
+	// * once disabled nothing is checked anymore
+	client.QuotaGuard.Disable()
+	if _, err := client.CreateObservableAnalysis(ctx, &observableParams); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	testWantData(t, 1, accessCalls)
+}
+
+func TestQuotaGuardRescan(t *testing.T) {
+	client, apiHandler, closeServer := setup()
+	defer closeServer()
+	ctx := context.Background()
+	apiHandler.HandleFunc(constants.USER_DETAILS_URL, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"user":{"username":"hussain"},"access":{"total_submissions":38,"month_submissions":9}}`))
+	})
+	rescans := 0
+	apiHandler.HandleFunc(fmt.Sprintf(constants.RESCAN_JOB_URL, 72), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		rescans++
+		w.Write([]byte(`{"id":73}`))
+	})
+	if err := client.QuotaGuard.Enable(gothreatmatrix.QuotaGuardOptions{MonthlyBudget: 10}); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	jobId, err := client.JobService.Rescan(ctx, 72)
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	testWantData(t, 73, jobId)
+	// * the rescan used the last submission of the month
+	if _, err := client.JobService.Rescan(ctx, 72); !errors.Is(err, gothreatmatrix.ErrQuotaExceeded) {
+		t.Fatalf("expected ErrQuotaExceeded, got: %v", err)
+	}
+	testWantData(t, 1, rescans)
+}
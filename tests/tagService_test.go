@@ -2,6 +2,7 @@ package tests
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"testing"
@@ -104,26 +105,37 @@ func TestTagServiceGet(t *testing.T) {
 	}
 }
 
+// tagListHandler answers the tag list with tagListJson and hands every other request to handler.
+func tagListHandler(tagListJson string, handler http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Write([]byte(tagListJson))
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}
+}
+
 func TestTagServiceCreate(t *testing.T) {
 	// *table test case
 	testCases := make(map[string]TestData)
 	testCases["simple"] = TestData{
 		Input: gothreatmatrix.TagParams{
 			Label: "TEST TAG",
-			Color: "#fffff",
+			Color: "#FFF",
 		},
-		Data:       `{"id": 1,"label": "TEST TAG","color": "#fffff"}`,
+		Data:       `{"id": 1,"label": "TEST TAG","color": "#ffffff"}`,
 		StatusCode: http.StatusOK,
 		Want: &gothreatmatrix.Tag{
 			ID:    1,
 			Label: "TEST TAG",
-			Color: "#fffff",
+			Color: "#ffffff",
 		},
 	}
 	testCases["duplicate"] = TestData{
 		Input: gothreatmatrix.TagParams{
 			Label: "TEST TAG",
-			Color: "#fffff",
+			Color: "#ffffff",
 		},
 		Data:       `{"label":["tag with this label already exists."]}`,
 		StatusCode: http.StatusBadRequest,
@@ -138,7 +150,7 @@ func TestTagServiceCreate(t *testing.T) {
 			client, apiHandler, closeServer := setup()
 			defer closeServer()
 			ctx := context.Background()
-			apiHandler.Handle(constants.BASE_TAG_URL, serverHandler(t, testCase, "POST"))
+			apiHandler.Handle(constants.BASE_TAG_URL, tagListHandler(`[]`, serverHandler(t, testCase, "POST")))
 			tagParams, ok := testCase.Input.(gothreatmatrix.TagParams)
 			if ok {
 				gottenTag, err := client.TagService.Create(ctx, &tagParams)
@@ -154,6 +166,40 @@ func TestTagServiceCreate(t *testing.T) {
 	}
 }
 
+func TestTagServiceCreateInvalid(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["invalidColor"] = TestData{
+		Input: gothreatmatrix.TagParams{
+			Label: "TEST TAG",
+			Color: "#fffff",
+		},
+	}
+	testCases["duplicateLabel"] = TestData{
+		Input: gothreatmatrix.TagParams{
+			Label: "test tag",
+			Color: "red",
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			sent := false
+			apiHandler.HandleFunc(constants.BASE_TAG_URL, tagListHandler(`[{"id": 1,"label": "TEST TAG","color": "#ffffff"}]`, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				sent = true
+			})))
+			tagParams, ok := testCase.Input.(gothreatmatrix.TagParams)
+			if ok {
+				if _, err := client.TagService.Create(ctx, &tagParams); err == nil {
+					t.Fatalf("Expected an error for %v", tagParams)
+				}
+				testWantData(t, false, sent)
+			}
+		})
+	}
+}
+
 func TestTagServiceUpdate(t *testing.T) {
 	// *table test case
 	testCases := make(map[string]TestData)
@@ -161,14 +207,14 @@ func TestTagServiceUpdate(t *testing.T) {
 		Input: gothreatmatrix.Tag{
 			ID:    1,
 			Label: "UPDATED TEST TAG",
-			Color: "#f4",
+			Color: "#f4f4f4",
 		},
-		Data:       `{"id": 1,"label": "UPDATED TEST TAG","color": "#f4"}`,
+		Data:       `{"id": 1,"label": "UPDATED TEST TAG","color": "#f4f4f4"}`,
 		StatusCode: http.StatusOK,
 		Want: &gothreatmatrix.Tag{
 			ID:    1,
 			Label: "UPDATED TEST TAG",
-			Color: "#f4",
+			Color: "#f4f4f4",
 		},
 	}
 	for name, testCase := range testCases {
@@ -179,6 +225,7 @@ func TestTagServiceUpdate(t *testing.T) {
 			ctx := context.Background()
 			tag, ok := testCase.Input.(gothreatmatrix.Tag)
 			if ok {
+				apiHandler.Handle(constants.BASE_TAG_URL, tagListHandler(`[{"id": 1,"label": "TEST TAG","color": "#ffffff"}]`, nil))
 				testUrl := fmt.Sprintf(constants.SPECIFIC_TAG_URL, tag.ID)
 				apiHandler.Handle(testUrl, serverHandler(t, testCase, "PUT"))
 				gottenTag, err := client.TagService.Update(ctx, tag.ID, &gothreatmatrix.TagParams{
@@ -195,6 +242,138 @@ func TestTagServiceUpdate(t *testing.T) {
 	}
 }
 
+func TestTagServiceGetByLabel(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input:      "test2",
+		Data:       `[{"id": 1,"label": "TEST1","color": "#1c71d8"},{"id": 2,"label": "TEST2","color": "#1c71d7"}]`,
+		StatusCode: http.StatusOK,
+		Want: &gothreatmatrix.Tag{
+			ID:    2,
+			Label: "TEST2",
+			Color: "#1c71d7",
+		},
+	}
+	testCases["notFound"] = TestData{
+		Input:      "TEST3",
+		Data:       `[{"id": 1,"label": "TEST1","color": "#1c71d8"}]`,
+		StatusCode: http.StatusOK,
+		Want:       gothreatmatrix.ErrTagNotFound,
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			apiHandler.Handle(constants.BASE_TAG_URL, serverHandler(t, testCase, "GET"))
+			label, ok := testCase.Input.(string)
+			if ok {
+				gottenTag, err := client.TagService.GetByLabel(ctx, label)
+				if wantError, isError := testCase.Want.(error); isError {
+					if !errors.Is(err, wantError) {
+						t.Fatalf("Error: %v, want %v", err, wantError)
+					}
+				} else if err != nil {
+					t.Fatalf("Error: %s", err)
+				} else {
+					testWantData(t, testCase.Want, gottenTag)
+				}
+			}
+		})
+	}
+}
+
+func TestTagServiceEnsureTag(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["exists"] = TestData{
+		Input: gothreatmatrix.TagParams{Label: "test1", Color: "blue"},
+		Want:  &gothreatmatrix.Tag{ID: 1, Label: "TEST1", Color: "#1c71d8"},
+	}
+	testCases["created"] = TestData{
+		Input: gothreatmatrix.TagParams{Label: "NEW", Color: "blue"},
+		Data:  `{"id": 3,"label": "NEW","color": "#0000ff"}`,
+		Want:  &gothreatmatrix.Tag{ID: 3, Label: "NEW", Color: "#0000ff"},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			apiHandler.Handle(constants.BASE_TAG_URL, tagListHandler(`[{"id": 1,"label": "TEST1","color": "#1c71d8"}]`, serverHandler(t, testCase, "POST")))
+			tagParams, ok := testCase.Input.(gothreatmatrix.TagParams)
+			if ok {
+				gottenTag, err := client.TagService.EnsureTag(ctx, &tagParams)
+				if err != nil {
+					t.Fatalf("Error: %s", err)
+				}
+				testWantData(t, testCase.Want, gottenTag)
+			}
+		})
+	}
+}
+
+func TestTagServiceSyncTags(t *testing.T) {
+	tagListJson := `[{"id": 1,"label": "TEST1","color": "#1c71d8"},{"id": 2,"label": "TEST2","color": "#1c71d7"},{"id": 3,"label": "stale","color": "#000000"}]`
+	desiredTags := []gothreatmatrix.TagParams{
+		{Label: "TEST1", Color: "#1C71D8"},
+		{Label: "test2", Color: "red"},
+		{Label: "NEW", Color: "#abc"},
+	}
+	wantPlan := &gothreatmatrix.TagSyncPlan{
+		Create: []gothreatmatrix.TagParams{{Label: "NEW", Color: "#aabbcc"}},
+		Update: []gothreatmatrix.TagUpdate{{
+			Tag:    gothreatmatrix.Tag{ID: 2, Label: "TEST2", Color: "#1c71d7"},
+			Params: gothreatmatrix.TagParams{Label: "test2", Color: "#ff0000"},
+		}},
+		Delete: []gothreatmatrix.Tag{{ID: 3, Label: "stale", Color: "#000000"}},
+	}
+	testCases := make(map[string]TestData)
+	testCases["dryRun"] = TestData{
+		Input: gothreatmatrix.TagSyncOptions{DryRun: true, Prune: true},
+		Want:  []string{},
+	}
+	testCases["apply"] = TestData{
+		Input: gothreatmatrix.TagSyncOptions{Prune: true},
+		Want:  []string{"DELETE /api/tags/3", "PUT /api/tags/2", "POST /api/tags"},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			requests := []string{}
+			recordRequest := func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.Path)
+				if r.Method == http.MethodDelete {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				w.Write([]byte(`{}`))
+			}
+			listCalls := 0
+			apiHandler.HandleFunc(constants.BASE_TAG_URL, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					listCalls++
+				}
+				tagListHandler(tagListJson, http.HandlerFunc(recordRequest)).ServeHTTP(w, r)
+			})
+			apiHandler.HandleFunc(constants.BASE_TAG_URL+"/", recordRequest)
+			options, ok := testCase.Input.(gothreatmatrix.TagSyncOptions)
+			if ok {
+				plan, err := client.TagService.SyncTags(ctx, desiredTags, &options)
+				if err != nil {
+					t.Fatalf("Error: %s", err)
+				}
+				wantPlan.Applied = !options.DryRun
+				testWantData(t, wantPlan, plan)
+				testWantData(t, testCase.Want, requests)
+				// * the tags are listed once for the whole sync
+				testWantData(t, 1, listCalls)
+			}
+		})
+	}
+}
+
 func TestTagServiceDelete(t *testing.T) {
 	// *table test case
 	testCases := make(map[string]TestData)
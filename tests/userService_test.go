@@ -3,6 +3,7 @@ package tests
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -169,3 +170,132 @@ func TestUserServiceInviteToOrganization(t *testing.T) {
 		})
 	}
 }
+
+func TestUserServiceMembers(t *testing.T) {
+	orgRespJsonStr := `{"members_count":3,"owner":{"username":"hussain","full_name":"h k","joined":"2022-07-23T09:11:08.674294Z"},"is_user_owner":true,"name":"StrawHats","members":[{"username":"hussain","full_name":"h k","joined":"2022-07-23T09:11:08.674294Z","is_admin":false},{"username":"zoro","full_name":"r z","joined":"2022-07-24T09:11:08.674294Z","is_admin":true},{"username":"usopp","full_name":"u s","joined":"2022-07-25T09:11:08.674294Z","is_admin":false}]}`
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Data:       orgRespJsonStr,
+		StatusCode: http.StatusOK,
+		Want:       []string{"hussain owner", "zoro admin", "usopp member"},
+	}
+	testCases["ownerNotListed"] = TestData{
+		Data:       `{"members_count":2,"owner":{"username":"hussain","full_name":"h k","joined":"2022-07-23T09:11:08.674294Z"},"is_user_owner":true,"name":"StrawHats","members":[{"username":"zoro","full_name":"r z","joined":"2022-07-24T09:11:08.674294Z","is_admin":true},{"username":"usopp","full_name":"u s","joined":"2022-07-25T09:11:08.674294Z","is_admin":false}]}`,
+		StatusCode: http.StatusOK,
+		Want:       []string{"hussain owner", "zoro admin", "usopp member"},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			apiHandler.Handle(constants.ORGANIZATION_URL, serverHandler(t, testCase, "GET"))
+			ctx := context.Background()
+			members, err := client.UserService.Members(ctx)
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			roles := []string{}
+			for _, member := range members {
+				roles = append(roles, member.Username+" "+string(member.Role()))
+			}
+			testWantData(t, testCase.Want, roles)
+		})
+	}
+}
+
+func TestUserServiceInvitations(t *testing.T) {
+	invitationsJsonStr := `[{"id":12,"created_at":"2022-07-24T18:43:42.299318Z","status":"pending","organization":{"members_count":2,"owner":{"username":"luffy"},"name":"StrawHats"}},{"id":13,"created_at":"2022-07-25T18:43:42.299318Z","status":"pending","organization":{"members_count":5,"owner":{"username":"shanks"},"name":"RedHair"}}]`
+	testCases := make(map[string]TestData)
+	testCases["all"] = TestData{
+		Input:      gothreatmatrix.InvitationParams{},
+		Data:       invitationsJsonStr,
+		StatusCode: http.StatusOK,
+		Want:       []int{12, 13},
+	}
+	testCases["organization"] = TestData{
+		Input: gothreatmatrix.InvitationParams{
+			Organization: gothreatmatrix.OrganizationParams{Name: "RedHair"},
+		},
+		Data:       invitationsJsonStr,
+		StatusCode: http.StatusOK,
+		Want:       []int{13},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			status := ""
+			apiHandler.HandleFunc(constants.INVITATIONS_URL, func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "GET")
+				status = r.URL.Query().Get("status")
+				w.Write([]byte(testCase.Data))
+			})
+			ctx := context.Background()
+			params := testCase.Input.(gothreatmatrix.InvitationParams)
+			invitations, err := client.UserService.Invitations(ctx, &params)
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			testWantData(t, gothreatmatrix.InvitationPending, status)
+			ids := []int{}
+			for _, invitation := range invitations {
+				ids = append(ids, invitation.Id)
+			}
+			testWantData(t, testCase.Want, ids)
+		})
+	}
+}
+
+func TestUserServiceAcceptInvitation(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input:      12,
+		Data:       `{}`,
+		StatusCode: http.StatusOK,
+		Want:       true,
+	}
+	testCases["notFound"] = TestData{
+		Input:      404,
+		Data:       `{"detail": "Not found."}`,
+		StatusCode: http.StatusNotFound,
+		Want: &gothreatmatrix.Error{
+			StatusCode: http.StatusNotFound,
+			Message:    `{"detail": "Not found."}`,
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			invitationId := testCase.Input.(int)
+			apiHandler.Handle(fmt.Sprintf(constants.ACCEPT_INVITATION_URL, invitationId), serverHandler(t, testCase, "POST"))
+			ctx := context.Background()
+			accepted, err := client.UserService.AcceptInvitation(ctx, invitationId)
+			if err != nil {
+				testError(t, testCase, err)
+			} else {
+				testWantData(t, testCase.Want, accepted)
+			}
+		})
+	}
+}
+
+func TestUserServicePromoteAdmin(t *testing.T) {
+	client, apiHandler, closeServer := setup()
+	defer closeServer()
+	memberParams := gothreatmatrix.MemberParams{}
+	apiHandler.HandleFunc(constants.PROMOTE_ADMIN_URL, func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		if err := json.NewDecoder(r.Body).Decode(&memberParams); err != nil {
+			t.Errorf("Error: %s", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	ctx := context.Background()
+	promoted, err := client.UserService.PromoteAdmin(ctx, &gothreatmatrix.MemberParams{Username: "zoro"})
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	testWantData(t, true, promoted)
+	testWantData(t, gothreatmatrix.MemberParams{Username: "zoro"}, memberParams)
+}
@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/khulnasoft/go-threatmatrix/constants"
+	"github.com/khulnasoft/go-threatmatrix/gothreatmatrix"
+)
+
+func TestVisualizerServiceGetConfigs(t *testing.T) {
+	visualizerConfigJsonString := `{
+		"Yara": {"name":"Yara","python_module":"yara.Yara","disabled":false,"playbooks":["Sample_Static_Analysis"]},
+		"DNS": {"name":"DNS","python_module":"dns.DNS","disabled":false,"playbooks":["Dns"]}
+	}`
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Data:       visualizerConfigJsonString,
+		StatusCode: http.StatusOK,
+		Want:       []string{"DNS", "Yara"},
+	}
+	testCases["serverError"] = TestData{
+		Data:       `{"error": "Error occurred by the server"}`,
+		StatusCode: http.StatusInternalServerError,
+		Want: &gothreatmatrix.Error{
+			StatusCode: http.StatusInternalServerError,
+			Message:    `{"error": "Error occurred by the server"}`,
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			apiHandler.Handle(constants.VISUALIZER_CONFIG_URL, serverHandler(t, testCase, "GET"))
+			visualizerConfigs, err := client.VisualizerService.GetConfigs(ctx)
+			if err != nil {
+				testError(t, testCase, err)
+			} else {
+				visualizerNames := []string{}
+				for _, visualizerConfig := range *visualizerConfigs {
+					visualizerNames = append(visualizerNames, visualizerConfig.Name)
+				}
+				testWantData(t, testCase.Want, visualizerNames)
+			}
+		})
+	}
+}
+
+func TestVisualizerServiceHealthCheck(t *testing.T) {
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Input:      "Yara",
+		Data:       `{"status": true}`,
+		StatusCode: http.StatusOK,
+		Want:       true,
+	}
+	testCases["visualizerDoesntExist"] = TestData{
+		Input:      "notAVisualizer",
+		Data:       `{"errors": {"detail": "Visualizer doesn't exist"}}`,
+		StatusCode: http.StatusBadRequest,
+		Want: &gothreatmatrix.Error{
+			StatusCode: http.StatusBadRequest,
+			Message:    `{"errors": {"detail": "Visualizer doesn't exist"}}`,
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, apiHandler, closeServer := setup()
+			defer closeServer()
+			ctx := context.Background()
+			input := testCase.Input.(string)
+			apiHandler.Handle(fmt.Sprintf(constants.VISUALIZER_HEALTHCHECK_URL, input), serverHandler(t, testCase, "GET"))
+			status, err := client.VisualizerService.HealthCheck(ctx, input)
+			if err != nil {
+				testError(t, testCase, err)
+			} else {
+				testWantData(t, testCase.Want, status)
+			}
+		})
+	}
+}
+
+func TestJobVisualizerReports(t *testing.T) {
+	jobJsonString := `{"id":72,"visualizer_reports":[{"name":"DNS","status":"SUCCESS","config":"DNS","type":"visualizer","errors":[],"process_time":0.2,
+		"report":[
+			{"level_position":1,"level_size":"3","elements":{"type":"horizontal_list","alignment":"around","values":[
+				{"type":"title","size":"auto","title":{"type":"base","value":"Classic DNS","bold":true},"value":{"type":"base","value":"2 resolutions","color":"info"}},
+				{"type":"bool","value":"Malicious","disable":true,"icon":"warning"},
+				{"type":"vertical_list","name":{"type":"base","value":"Resolutions"},"add_count_in_title":true,"values":[{"type":"base","value":"dns.google","link":"https://dns.google","copy_text":"dns.google"},{"type":"base","value":42}]}
+			]}},
+			{"level_position":2,"level_size":"5","elements":{"type":"horizontal_list","values":[
+				{"type":"table","page_size":10,"columns":["name",{"name":"ttl","max_width":100,"disable_sort_by":true}],"data":[{"name":{"type":"base","value":"dns.google"},"ttl":{"type":"base","value":"300"}}]}
+			]}}
+		]}]}`
+	testCases := make(map[string]TestData)
+	testCases["simple"] = TestData{
+		Data: jobJsonString,
+		Want: []string{"horizontal_list", "title", "base:Classic DNS", "base:2 resolutions", "bool:Malicious", "vertical_list", "base:Resolutions", "base:dns.google", "base:42", "horizontal_list", "table", "base:dns.google", "base:300"},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			job := gothreatmatrix.Job{}
+			if err := json.Unmarshal([]byte(testCase.Data), &job); err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			report := job.VisualizerReports[0]
+			testWantData(t, "DNS", report.Config)
+			testWantData(t, []int{1, 2}, []int{report.Levels[0].Position, report.Levels[1].Position})
+			// * walking the elements the way a dashboard renders them
+			var walk func(element *gothreatmatrix.VisualizerElement) []string
+			walk = func(element *gothreatmatrix.VisualizerElement) []string {
+				node := element.Type
+				if element.Value != "" {
+					node += ":" + element.Value
+				}
+				nodes := []string{node}
+				for _, child := range element.Children() {
+					nodes = append(nodes, walk(child)...)
+				}
+				return nodes
+			}
+			nodes := []string{}
+			for index := range report.Levels {
+				nodes = append(nodes, walk(&report.Levels[index].Elements)...)
+			}
+			testWantData(t, testCase.Want, nodes)
+			table := report.Levels[1].Elements.Values[0]
+			testWantData(t, []gothreatmatrix.VisualizerTableColumn{{Name: "name"}, {Name: "ttl", MaxWidth: 100, DisableSortBy: true}}, table.Columns)
+
+			// * the report survives a round trip for our own dashboard
+			reportJson, err := json.Marshal(report)
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			decoded := gothreatmatrix.VisualizerReport{}
+			if err := json.Unmarshal(reportJson, &decoded); err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			testWantData(t, report, decoded)
+		})
+	}
+}
+
+func TestJobVisualizerReportsPending(t *testing.T) {
+	jobJsonString := `{"id":73,"visualizer_reports":[
+		{"name":"DNS","status":"PENDING","config":"DNS","type":"visualizer","errors":[],"report":{}},
+		{"name":"Yara","status":"FAILED","config":"Yara","type":"visualizer","errors":["timeout"],"report":{}}
+	]}`
+	job := gothreatmatrix.Job{}
+	if err := json.Unmarshal([]byte(jobJsonString), &job); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	statuses := []string{}
+	for _, report := range job.VisualizerReports {
+		statuses = append(statuses, fmt.Sprintf("%s %s %d", report.Name, report.Status, len(report.Levels)))
+	}
+	testWantData(t, []string{"DNS PENDING 0", "Yara FAILED 0"}, statuses)
+	testWantData(t, []string{"timeout"}, job.VisualizerReports[1].Errors)
+}